@@ -0,0 +1,353 @@
+package resource
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Roles   []string
+	Claims  map[string]any
+}
+
+func (p *Principal) HasRole(role string) bool {
+	if p == nil {
+		return false
+	}
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNoCredentials is returned by authenticators when the request does not
+// carry their kind of credentials, so that the next one can be tried.
+var ErrNoCredentials = NewError(http.StatusUnauthorized, "NoCredentials", "missing credentials")
+
+// Authenticator returns ErrNoCredentials when the request has none of its
+// credentials, and an error with status 401, e.g. ErrUnauthorized, when they
+// are invalid. Other errors are reported as 500 Internal Server Error.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+	// SecurityScheme describes the authenticator in the OpenAPI document.
+	SecurityScheme() SecurityScheme
+}
+
+// SecurityScheme mirrors the OpenAPI security scheme object.
+type SecurityScheme struct {
+	Name         string
+	Type         string // http or apiKey
+	Scheme       string // bearer or basic, for http
+	BearerFormat string
+	In           string // header, query or cookie, for apiKey
+	ParamName    string
+}
+
+// challenge returns the WWW-Authenticate challenge of the scheme, e.g.
+// Bearer realm="users". API keys have no HTTP authentication scheme, so
+// they are challenged with a made up APIKey scheme naming the parameter.
+func (s SecurityScheme) challenge(realm string) string {
+	switch {
+	case s.Type == "http" && s.Scheme != "":
+		return fmt.Sprintf("%s%s realm=%q", strings.ToUpper(s.Scheme[:1]), s.Scheme[1:], realm)
+	case s.Type == "apiKey":
+		return fmt.Sprintf("APIKey realm=%q, in=%q, name=%q", realm, s.In, s.ParamName)
+	}
+	return ""
+}
+
+// AuthorizationRequest is what an Authorizer decides on. ID is empty for
+// operations on the collection.
+type AuthorizationRequest struct {
+	Principal   *Principal
+	Resource    string
+	Operation   Operation
+	ID          string
+	Subresource string
+}
+
+// Authorizer returns nil to allow the request. Errors without a status code
+// of their own are reported as 403 Forbidden.
+type Authorizer func(ctx Context, req AuthorizationRequest) error
+
+// Authenticate requires every request to be authenticated by one of the
+// authenticators, tried in order. The principal is available with Context.Principal.
+func (r *Resource[T]) Authenticate(authenticators ...Authenticator) *Resource[T] {
+	r.authenticators = append(r.authenticators, authenticators...)
+	return r
+}
+
+// Authorize sets the authorizer consulted before every operation. The items
+// of batch updates and deletes are authorized one by one as well, with
+// OperationUpdate or OperationDelete and their ID, and those denied fail
+// with 403 Forbidden.
+func (r *Resource[T]) Authorize(authorizer Authorizer) *Resource[T] {
+	r.authorizer = authorizer
+	return r
+}
+
+// allAuthenticators returns the authenticators of the resource, or those
+// inherited from its parents when it has none of its own.
+func (b *Resource[T]) allAuthenticators() []Authenticator {
+	if len(b.authenticators) == 0 && b.parent != nil {
+		return b.parent.allAuthenticators()
+	}
+	return b.authenticators
+}
+
+// authorizes reports whether the resource or one of its parents has an authorizer.
+func (b *Resource[T]) authorizes() bool {
+	return b.authorizer != nil || (b.parent != nil && b.parent.authorizes())
+}
+
+// authorizeItem asks the authorizers of the resource and of its parents
+// whether the principal may get the item addressed by the request.
+func (b *Resource[T]) authorizeItem(r *http.Request) error {
+	if b.parent != nil {
+		if err := b.parent.authorizeItem(r); err != nil {
+			return err
+		}
+	}
+	if b.authorizer == nil {
+		return nil
+	}
+	ctx := newContext(r)
+	return b.authorizer(ctx, AuthorizationRequest{
+		Principal: ctx.Principal(),
+		Resource:  b.name,
+		Operation: OperationGet,
+		ID:        r.PathValue(b.pathID()),
+	})
+}
+
+// authorizeBatchItem asks the authorizer whether the principal may perform
+// op on the item of a batch with the given id. Items without id are left to
+// validation.
+func (b *Resource[T]) authorizeBatchItem(ctx Context, op Operation, id string) error {
+	if b.authorizer == nil || id == "" {
+		return nil
+	}
+	return b.authorizer(ctx, AuthorizationRequest{
+		Principal: ctx.Principal(),
+		Resource:  b.name,
+		Operation: op,
+		ID:        id,
+	})
+}
+
+type principalKey struct{}
+
+func withPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// Principal returns the authenticated caller, or nil for anonymous requests.
+func (c Context) Principal() *Principal {
+	p, _ := c.Value(principalKey{}).(*Principal)
+	return p
+}
+
+func (b *Resource[T]) authMiddleware(route route) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if authenticators := b.allAuthenticators(); len(authenticators) > 0 {
+				principal, err := authenticate(r, authenticators)
+				if err != nil {
+					// errors without a status, e.g. of an unavailable credential
+					// store, say nothing about the credentials
+					if statusFromError(err, http.StatusInternalServerError) == http.StatusUnauthorized {
+						for _, a := range authenticators {
+							if challenge := a.SecurityScheme().challenge(b.plural); challenge != "" {
+								w.Header().Add(HeaderWWWAuthenticate, challenge)
+							}
+						}
+					}
+					WriteError(w, http.StatusInternalServerError, err)
+					return
+				}
+				r = r.WithContext(withPrincipal(r.Context(), principal))
+			}
+			if b.parent != nil {
+				if err := b.parent.authorizeItem(r); err != nil {
+					WriteError(w, http.StatusForbidden, err)
+					return
+				}
+			}
+			if b.authorizer != nil {
+				ctx := newContext(r)
+				err := b.authorizer(ctx, AuthorizationRequest{
+					Principal:   ctx.Principal(),
+					Resource:    b.name,
+					Operation:   route.Operation,
+					ID:          r.PathValue(b.pathID()),
+					Subresource: route.Subresource,
+				})
+				if err != nil {
+					WriteError(w, http.StatusForbidden, err)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func authenticate(r *http.Request, authenticators []Authenticator) (*Principal, error) {
+	for _, a := range authenticators {
+		principal, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if principal == nil {
+			return nil, ErrUnauthorized
+		}
+		return principal, nil
+	}
+	return nil, ErrNoCredentials
+}
+
+// BearerAuth authenticates "Authorization: Bearer <token>" with verify.
+func BearerAuth(verify func(ctx context.Context, token string) (*Principal, error)) Authenticator {
+	return bearerAuth{verify: verify}
+}
+
+type bearerAuth struct {
+	verify       func(ctx context.Context, token string) (*Principal, error)
+	bearerFormat string
+}
+
+func (a bearerAuth) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+	return a.verify(r.Context(), strings.TrimSpace(token))
+}
+
+func (a bearerAuth) SecurityScheme() SecurityScheme {
+	return SecurityScheme{Name: "bearerAuth", Type: "http", Scheme: "bearer", BearerFormat: a.bearerFormat}
+}
+
+// JWTAuth authenticates HS256 signed JSON Web Tokens sent as bearer tokens.
+// The sub claim becomes the subject of the principal, and the roles claim
+// its roles. Expired or not yet valid tokens are rejected.
+func JWTAuth(secret []byte) Authenticator {
+	return bearerAuth{
+		bearerFormat: "JWT",
+		verify: func(ctx context.Context, token string) (*Principal, error) {
+			return verifyJWT(secret, token, time.Now())
+		},
+	}
+}
+
+func verifyJWT(secret []byte, token string, now time.Time) (*Principal, error) {
+	invalid := func(reason string) error {
+		return ErrUnauthorized.WithMessage("invalid token: " + reason)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("malformed")
+	}
+	enc := base64.RawURLEncoding
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	raw, err := enc.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(raw, &header) != nil {
+		return nil, invalid("malformed header")
+	}
+	if header.Alg != "HS256" {
+		return nil, invalid(fmt.Sprintf("unsupported algorithm %q", header.Alg))
+	}
+	signature, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed signature")
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, invalid("signature mismatch")
+	}
+
+	var claims map[string]any
+	raw, err = enc.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(raw, &claims) != nil {
+		return nil, invalid("malformed claims")
+	}
+	if exp, ok := claims["exp"].(float64); ok && now.Unix() >= int64(exp) {
+		return nil, invalid("expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Unix() < int64(nbf) {
+		return nil, invalid("not valid yet")
+	}
+
+	principal := &Principal{Claims: claims}
+	principal.Subject, _ = claims["sub"].(string)
+	if roles, ok := claims["roles"].([]any); ok {
+		for _, role := range roles {
+			if s, ok := role.(string); ok {
+				principal.Roles = append(principal.Roles, s)
+			}
+		}
+	}
+	return principal, nil
+}
+
+// APIKeyAuth authenticates the API key sent in the given header with lookup.
+func APIKeyAuth(header string, lookup func(ctx context.Context, key string) (*Principal, error)) Authenticator {
+	return apiKeyAuth{header: header, lookup: lookup}
+}
+
+type apiKeyAuth struct {
+	header string
+	lookup func(ctx context.Context, key string) (*Principal, error)
+}
+
+func (a apiKeyAuth) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(a.header)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	return a.lookup(r.Context(), key)
+}
+
+func (a apiKeyAuth) SecurityScheme() SecurityScheme {
+	return SecurityScheme{Name: "apiKeyAuth", Type: "apiKey", In: "header", ParamName: a.header}
+}
+
+// BasicAuth authenticates HTTP basic credentials with verify.
+func BasicAuth(verify func(ctx context.Context, username string, password string) (*Principal, error)) Authenticator {
+	return basicAuth{verify: verify}
+}
+
+type basicAuth struct {
+	verify func(ctx context.Context, username string, password string) (*Principal, error)
+}
+
+func (a basicAuth) Authenticate(r *http.Request) (*Principal, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	return a.verify(r.Context(), username, password)
+}
+
+func (a basicAuth) SecurityScheme() SecurityScheme {
+	return SecurityScheme{Name: "basicAuth", Type: "http", Scheme: "basic"}
+}
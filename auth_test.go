@@ -0,0 +1,127 @@
+package resource_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iwanhae/resource"
+)
+
+func signJWT(secret []byte, claims map[string]any) string {
+	enc := base64.RawURLEncoding
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuth(t *testing.T) {
+	secret := []byte("secret")
+	var seen resource.AuthorizationRequest
+
+	handler := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		Get(func(ctx resource.Context, id string) (MockResource, error) {
+			return MockResource{ID: id, Name: ctx.Principal().Subject}, nil
+		}).
+		Delete(mockDelete).
+		Authenticate(
+			resource.JWTAuth(secret),
+			resource.APIKeyAuth("X-API-Key", func(ctx context.Context, key string) (*resource.Principal, error) {
+				switch key {
+				case "valid":
+				case "outage":
+					return nil, errors.New("db down")
+				default:
+					return nil, resource.ErrUnauthorized.WithMessage("unknown api key")
+				}
+				return &resource.Principal{Subject: "service"}, nil
+			}),
+			resource.BasicAuth(func(ctx context.Context, username string, password string) (*resource.Principal, error) {
+				if password != "hunter2" {
+					return nil, resource.ErrUnauthorized
+				}
+				return &resource.Principal{Subject: username}, nil
+			}),
+		).
+		Authorize(func(ctx resource.Context, req resource.AuthorizationRequest) error {
+			seen = req
+			if req.Operation == resource.OperationDelete && !req.Principal.HasRole("admin") {
+				return resource.ErrForbidden.WithMessage("admins only")
+			}
+			return nil
+		}).
+		Handler()
+
+	user := "Bearer " + signJWT(secret, map[string]any{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+	admin := "Bearer " + signJWT(secret, map[string]any{"sub": "bob", "roles": []string{"admin"}})
+	expired := "Bearer " + signJWT(secret, map[string]any{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix()})
+	forged := "Bearer " + signJWT([]byte("other"), map[string]any{"sub": "alice"})
+
+	testCases := []struct {
+		name            string
+		method          string
+		header          string
+		value           string
+		expectedStatus  int
+		expectedSubject string
+	}{
+		{"Anonymous", "GET", "", "", http.StatusUnauthorized, ""},
+		{"JWT", "GET", "Authorization", user, http.StatusOK, "alice"},
+		{"ExpiredJWT", "GET", "Authorization", expired, http.StatusUnauthorized, ""},
+		{"ForgedJWT", "GET", "Authorization", forged, http.StatusUnauthorized, ""},
+		{"APIKey", "GET", "X-API-Key", "valid", http.StatusOK, "service"},
+		{"InvalidAPIKey", "GET", "X-API-Key", "invalid", http.StatusUnauthorized, ""},
+		{"APIKeyStoreDown", "GET", "X-API-Key", "outage", http.StatusInternalServerError, ""},
+		{"Basic", "GET", "Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte("carol:hunter2")), http.StatusOK, "carol"},
+		{"Forbidden", "DELETE", "Authorization", user, http.StatusForbidden, ""},
+		{"Allowed", "DELETE", "Authorization", admin, http.StatusNoContent, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/mocks/1", strings.NewReader(""))
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			challenges := rr.Header().Values("WWW-Authenticate")
+			if rr.Code == http.StatusUnauthorized {
+				expected := []string{`Bearer realm="mocks"`, `APIKey realm="mocks", in="header", name="X-API-Key"`, `Basic realm="mocks"`}
+				if !slices.Equal(challenges, expected) {
+					t.Errorf("unexpected challenges: got %q want %q", challenges, expected)
+				}
+			} else if len(challenges) > 0 {
+				t.Errorf("unexpected challenges: %q", challenges)
+			}
+			if tc.expectedSubject == "" {
+				return
+			}
+			var got MockResource
+			json.NewDecoder(rr.Body).Decode(&got)
+			if got.Name != tc.expectedSubject {
+				t.Errorf("unexpected principal: got %v want %v", got.Name, tc.expectedSubject)
+			}
+			if seen.Resource != "mock" || seen.Operation != resource.OperationGet || seen.ID != "1" {
+				t.Errorf("unexpected authorization request: %+v", seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	OperationBatchCreate Operation = "batchCreate"
+	OperationBatchUpdate Operation = "batchUpdate"
+	OperationBatchDelete Operation = "batchDelete"
+)
+
+// BatchCreate creates every item, returning them in the same order.
+type BatchCreate[T Validator] func(ctx Context, items []T) ([]T, error)
+
+// BatchUpdate updates every item, returning them in the same order.
+type BatchUpdate[T Validator] func(ctx Context, items []BatchUpdateItem[T]) ([]T, error)
+
+// BatchDelete deletes the resources with the given ids.
+type BatchDelete[T Validator] func(ctx Context, ids []string) error
+
+// BatchError is returned by batch callbacks when some of the items failed.
+// It maps indices of the items passed to the callback to their error. Any
+// other error fails the whole batch.
+type BatchError map[int]error
+
+func (e BatchError) Error() string {
+	indices := make([]int, 0, len(e))
+	for i := range e {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	msgs := make([]string, len(indices))
+	for n, i := range indices {
+		msgs[n] = strconv.Itoa(i) + ": " + e[i].Error()
+	}
+	return fmt.Sprintf("%d items of the batch failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+type BatchCreateRequest[T Validator] struct {
+	Items []T `json:"items"`
+}
+
+type BatchUpdateItem[T Validator] struct {
+	ID   string `json:"id"`
+	Item T      `json:"item"`
+}
+
+type BatchUpdateRequest[T Validator] struct {
+	Items []BatchUpdateItem[T] `json:"items"`
+}
+
+type BatchDeleteRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// BatchResponse reports the outcome of every item of a batch, in the order
+// of the request.
+type BatchResponse[T Validator] struct {
+	Items []BatchResult[T] `json:"items"`
+}
+
+type BatchResult[T Validator] struct {
+	Status int            `json:"status"`
+	ID     string         `json:"id,omitempty"`
+	Item   *T             `json:"item,omitempty"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
+
+// BatchCreate serves POST {plural}:batchCreate.
+func (r *Resource[T]) BatchCreate(fn BatchCreate[T]) *Resource[T] {
+	r.batchCreate = fn
+	return r
+}
+
+// BatchUpdate serves POST {plural}:batchUpdate.
+func (r *Resource[T]) BatchUpdate(fn BatchUpdate[T]) *Resource[T] {
+	r.batchUpdate = fn
+	return r
+}
+
+// BatchDelete serves POST {plural}:batchDelete.
+func (r *Resource[T]) BatchDelete(fn BatchDelete[T]) *Resource[T] {
+	r.batchDelete = fn
+	return r
+}
+
+// PartialBatches lets batches succeed for some of their items only, which
+// is then answered with 207 Multi-Status. By default batches are all or
+// nothing: when an item fails the batch is answered with 422 Unprocessable
+// Entity, the failed items carrying their own status and the others 424
+// Failed Dependency, and the callback is not called for invalid items.
+// Callbacks returning a BatchError in that mode must have rolled back the
+// other items.
+func (r *Resource[T]) PartialBatches(enabled bool) *Resource[T] {
+	r.partialBatches = enabled
+	return r
+}
+
+// MaxBatchSize limits the number of items of a batch. Defaults to 1000.
+func (r *Resource[T]) MaxBatchSize(n int) *Resource[T] {
+	r.maxBatchSize = n
+	return r
+}
+
+func (b *Resource[T]) handlerBatchCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r)
+	var req BatchCreateRequest[T]
+	if err := b.decodeBatch(r, &req, func() int { return len(req.Items) }); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	b.runBatch(w, len(req.Items), http.StatusCreated,
+		func(i int) string { return "" },
+		func(i int) error { return nil },
+		func(i int) error { return validateCreate(ctx, req.Items[i]) },
+		func(indices []int) ([]T, error) {
+			items := make([]T, len(indices))
+			for n, i := range indices {
+				items[n] = req.Items[i]
+			}
+			return b.batchCreate(ctx, items)
+		})
+}
+
+func (b *Resource[T]) handlerBatchUpdate(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r)
+	var req BatchUpdateRequest[T]
+	if err := b.decodeBatch(r, &req, func() int { return len(req.Items) }); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	b.runBatch(w, len(req.Items), http.StatusOK,
+		func(i int) string { return req.Items[i].ID },
+		func(i int) error { return b.authorizeBatchItem(ctx, OperationUpdate, req.Items[i].ID) },
+		func(i int) error {
+			if req.Items[i].ID == "" {
+				return ErrBadRequest.WithMessage("missing id")
+			}
+			return validateUpdate(ctx, req.Items[i].ID, req.Items[i].Item)
+		},
+		func(indices []int) ([]T, error) {
+			items := make([]BatchUpdateItem[T], len(indices))
+			for n, i := range indices {
+				items[n] = req.Items[i]
+			}
+			return b.batchUpdate(ctx, items)
+		})
+}
+
+func (b *Resource[T]) handlerBatchDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r)
+	var req BatchDeleteRequest
+	if err := b.decodeBatch(r, &req, func() int { return len(req.IDs) }); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	b.runBatch(w, len(req.IDs), http.StatusNoContent,
+		func(i int) string { return req.IDs[i] },
+		func(i int) error { return b.authorizeBatchItem(ctx, OperationDelete, req.IDs[i]) },
+		func(i int) error {
+			if req.IDs[i] == "" {
+				return ErrBadRequest.WithMessage("missing id")
+			}
+			return nil
+		},
+		func(indices []int) ([]T, error) {
+			ids := make([]string, len(indices))
+			for n, i := range indices {
+				ids[n] = req.IDs[i]
+			}
+			return nil, b.batchDelete(ctx, ids)
+		})
+}
+
+func (b *Resource[T]) decodeBatch(r *http.Request, req any, size func() int) error {
+	if err := b.decode(r, req); err != nil {
+		return err
+	}
+	if n := size(); n > b.maxBatchSize {
+		return ErrBadRequest.WithMessage(fmt.Sprintf("batch of %d items exceeds the limit of %d", n, b.maxBatchSize))
+	}
+	return nil
+}
+
+// runBatch authorizes and validates the n items of a batch, passes the valid
+// ones to call and writes the BatchResponse. call returns the resulting items
+// in the order of indices, or nil when there are none.
+func (b *Resource[T]) runBatch(w http.ResponseWriter, n int, success int,
+	id func(i int) string, authorize func(i int) error, validate func(i int) error, call func(indices []int) ([]T, error)) {
+	results := make([]BatchResult[T], n)
+	failed := false
+	fail := func(i int, code int, err error) {
+		code, res := newErrorResponse(code, err)
+		results[i].Status = code
+		results[i].Error = &res
+		failed = true
+	}
+
+	var valid []int
+	for i := range results {
+		results[i].ID = id(i)
+		if err := authorize(i); err != nil {
+			fail(i, http.StatusForbidden, err)
+			continue
+		}
+		if err := validate(i); err != nil {
+			fail(i, http.StatusBadRequest, err)
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	if len(valid) > 0 && (!failed || b.partialBatches) {
+		items, err := call(valid)
+		var batchErr BatchError
+		switch {
+		case errors.As(err, &batchErr):
+			for j, err := range batchErr {
+				if j >= 0 && j < len(valid) {
+					fail(valid[j], http.StatusInternalServerError, err)
+				}
+			}
+		case err != nil:
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if items != nil && len(items) != len(valid) {
+			WriteError(w, http.StatusInternalServerError, fmt.Errorf("batch callback returned %d items for %d", len(items), len(valid)))
+			return
+		}
+		for j, i := range valid {
+			if results[i].Error != nil {
+				continue
+			}
+			results[i].Status = success
+			if items != nil {
+				results[i].Item = &items[j]
+			}
+		}
+	}
+
+	status := http.StatusOK
+	switch {
+	case !failed:
+	case b.partialBatches:
+		status = http.StatusMultiStatus
+	default:
+		status = http.StatusUnprocessableEntity
+		for i := range results {
+			if results[i].Error == nil {
+				results[i].Status = http.StatusFailedDependency
+				results[i].Item = nil
+			}
+		}
+	}
+	Write(w, status, BatchResponse[T]{Items: results})
+}
@@ -0,0 +1,175 @@
+package resource_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+func newBatchResource(partial bool) *resource.Resource[MockResource] {
+	return resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		PartialBatches(partial).
+		BatchCreate(func(ctx resource.Context, items []MockResource) ([]MockResource, error) {
+			for i := range items {
+				items[i].ID = items[i].Name
+			}
+			return items, nil
+		}).
+		BatchUpdate(func(ctx resource.Context, items []resource.BatchUpdateItem[MockResource]) ([]MockResource, error) {
+			result := make([]MockResource, len(items))
+			failed := resource.BatchError{}
+			for i, item := range items {
+				if item.ID == "missing" {
+					failed[i] = resource.ErrNotFound
+				}
+				result[i] = item.Item
+				result[i].ID = item.ID
+			}
+			if len(failed) > 0 {
+				return result, failed
+			}
+			return result, nil
+		}).
+		BatchDelete(func(ctx resource.Context, ids []string) error {
+			if len(ids) > 2 {
+				return errors.New("database unavailable")
+			}
+			return nil
+		})
+}
+
+func TestBatch(t *testing.T) {
+	testCases := []struct {
+		name             string
+		partial          bool
+		target           string
+		body             string
+		expectedStatus   int
+		expectedStatuses []int
+	}{
+		{"CreateAll", false, "/mocks:batchCreate", `{"items":[{"name":"a"},{"name":"b"}]}`, http.StatusOK, []int{201, 201}},
+		{"CreateAtomicInvalid", false, "/mocks:batchCreate", `{"items":[{"name":"a"},{}]}`, http.StatusUnprocessableEntity, []int{424, 400}},
+		{"CreatePartialInvalid", true, "/mocks:batchCreate", `{"items":[{"name":"a"},{}]}`, http.StatusMultiStatus, []int{201, 400}},
+		{"UpdatePartialNotFound", true, "/mocks:batchUpdate", `{"items":[{"id":"missing","item":{"name":"a"}},{"id":"1","item":{"name":"b"}}]}`, http.StatusMultiStatus, []int{404, 200}},
+		{"UpdateAtomicNotFound", false, "/mocks:batchUpdate", `{"items":[{"id":"1","item":{"name":"a"}},{"id":"missing","item":{"name":"b"}}]}`, http.StatusUnprocessableEntity, []int{424, 404}},
+		{"UpdateMissingID", true, "/mocks:batchUpdate", `{"items":[{"item":{"name":"a"}}]}`, http.StatusMultiStatus, []int{400}},
+		{"Delete", false, "/mocks:batchDelete", `{"ids":["1","2"]}`, http.StatusOK, []int{204, 204}},
+		{"DeleteFails", false, "/mocks:batchDelete", `{"ids":["1","2","3"]}`, http.StatusInternalServerError, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tc.target, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			newBatchResource(tc.partial).Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					rr.Code, tc.expectedStatus)
+			}
+			if tc.expectedStatuses == nil {
+				return
+			}
+			var res resource.BatchResponse[MockResource]
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatalf("Could not decode batch response: %v", err)
+			}
+			var statuses []int
+			for _, item := range res.Items {
+				statuses = append(statuses, item.Status)
+				if item.Status == http.StatusCreated && (item.Item == nil || item.Item.ID == "") {
+					t.Errorf("expected the created item, got %+v", item)
+				}
+				if item.Status >= 400 && item.Status != http.StatusFailedDependency && item.Error == nil {
+					t.Errorf("expected an error, got %+v", item)
+				}
+			}
+			got, _ := json.Marshal(statuses)
+			expected, _ := json.Marshal(tc.expectedStatuses)
+			if string(got) != string(expected) {
+				t.Errorf("Unexpected item statuses: got %s want %s", got, expected)
+			}
+		})
+	}
+}
+
+func TestBatchSizeLimit(t *testing.T) {
+	r := newBatchResource(false).MaxBatchSize(1)
+	req := httptest.NewRequest("POST", "/mocks:batchDelete", strings.NewReader(`{"ids":["1","2"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v",
+			rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestBatchAuthorization(t *testing.T) {
+	var seen []resource.AuthorizationRequest
+	r := newBatchResource(true).
+		Authorize(func(ctx resource.Context, req resource.AuthorizationRequest) error {
+			seen = append(seen, req)
+			if req.ID == "other" {
+				return errors.New("not yours")
+			}
+			return nil
+		})
+
+	testCases := []struct {
+		name             string
+		target           string
+		body             string
+		expectedStatuses []int
+		expectedSeen     string
+	}{
+		{"Update", "/mocks:batchUpdate", `{"items":[{"id":"1","item":{"name":"a"}},{"id":"other","item":{"name":"b"}}]}`,
+			[]int{200, 403}, "batchUpdate: update 1: update other"},
+		{"Delete", "/mocks:batchDelete", `{"ids":["other","2"]}`,
+			[]int{403, 204}, "batchDelete: delete other: delete 2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			seen = nil
+			req := httptest.NewRequest("POST", tc.target, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusMultiStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					rr.Code, http.StatusMultiStatus)
+			}
+			var res resource.BatchResponse[MockResource]
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatalf("Could not decode batch response: %v", err)
+			}
+			var statuses []int
+			for _, item := range res.Items {
+				statuses = append(statuses, item.Status)
+			}
+			got, _ := json.Marshal(statuses)
+			expected, _ := json.Marshal(tc.expectedStatuses)
+			if string(got) != string(expected) {
+				t.Errorf("Unexpected item statuses: got %s want %s", got, expected)
+			}
+			var requests []string
+			for _, req := range seen {
+				requests = append(requests, strings.TrimSpace(string(req.Operation)+" "+req.ID))
+			}
+			if got := strings.Join(requests, ": "); got != tc.expectedSeen {
+				t.Errorf("Unexpected authorization requests: got %q want %q", got, tc.expectedSeen)
+			}
+		})
+	}
+}
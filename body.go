@@ -0,0 +1,109 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxBodySize limits request bodies to n bytes. Larger ones are rejected
+// with 413 Request Entity Too Large. Zero, the default, means no limit.
+func (r *Resource[T]) MaxBodySize(n int64) *Resource[T] {
+	r.maxBodySize = n
+	return r
+}
+
+// StrictJSON rejects JSON bodies with unknown fields, duplicate keys or
+// data after the value. It applies to JSONCodec and to patches.
+func (r *Resource[T]) StrictJSON(enabled bool) *Resource[T] {
+	r.strictJSON = enabled
+	return r
+}
+
+// RequireContentType rejects bodies without a Content-Type header with 415,
+// instead of decoding them with the first codec.
+func (r *Resource[T]) RequireContentType(enabled bool) *Resource[T] {
+	r.requireContentType = enabled
+	return r
+}
+
+func (b *Resource[T]) bodyLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, b.maxBodySize)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bodyError reports errors reading the request body, which are either
+// caused by MaxBodySize or by malformed content.
+func bodyError(err error) error {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return ErrPayloadTooLarge.WithMessage(fmt.Sprintf("request body exceeds %d bytes", maxBytes.Limit))
+	}
+	return ErrBadRequest.Wrap(err)
+}
+
+// checkContentType enforces RequireContentType.
+func (b *Resource[T]) checkContentType(r *http.Request) error {
+	if b.requireContentType && r.Header.Get(HeaderContentType) == "" {
+		return ErrUnsupportedMediaType.WithMessage("missing Content-Type")
+	}
+	return nil
+}
+
+// unmarshalStrict is json.Unmarshal, failing on unknown fields, duplicate
+// keys and trailing data.
+func unmarshalStrict(raw []byte, v any) error {
+	if err := checkDuplicateKeys(json.NewDecoder(bytes.NewReader(raw)), ""); err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// checkDuplicateKeys reads one value from d, failing on objects with
+// duplicate keys. pointer is the location of the value, for errors.
+func checkDuplicateKeys(d *json.Decoder, pointer string) error {
+	token, err := d.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		keys := make(map[string]bool)
+		for d.More() {
+			token, err := d.Token()
+			if err != nil {
+				return err
+			}
+			key := token.(string)
+			if keys[key] {
+				return fmt.Errorf("duplicate key %q at %q", key, pointer)
+			}
+			keys[key] = true
+			if err := checkDuplicateKeys(d, pointer+Pointer(key)); err != nil {
+				return err
+			}
+		}
+		_, err = d.Token()
+	case json.Delim('['):
+		for i := 0; d.More(); i++ {
+			if err := checkDuplicateKeys(d, pointer+Pointer(i)); err != nil {
+				return err
+			}
+		}
+		_, err = d.Token()
+	}
+	return err
+}
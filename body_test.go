@@ -0,0 +1,58 @@
+package resource_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+func TestBodyLimits(t *testing.T) {
+	r := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		MaxBodySize(32).
+		StrictJSON(true).
+		RequireContentType(true).
+		Create(mockCreate).
+		Get(mockGet).
+		Patch(func(ctx resource.Context, id string, m MockResource) (MockResource, error) { return m, nil })
+
+	testCases := []struct {
+		name           string
+		method         string
+		target         string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{"Valid", "POST", "/mocks", "application/json", `{"name":"a"}`, http.StatusCreated},
+		{"TooLarge", "POST", "/mocks", "application/json", `{"name":"` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge},
+		{"UnknownField", "POST", "/mocks", "application/json", `{"name":"a","age":1}`, http.StatusBadRequest},
+		{"DuplicateKey", "POST", "/mocks", "application/json", `{"name":"a","name":"b"}`, http.StatusBadRequest},
+		{"TrailingData", "POST", "/mocks", "application/json", `{"name":"a"} {}`, http.StatusBadRequest},
+		{"MissingContentType", "POST", "/mocks", "", `{"name":"a"}`, http.StatusUnsupportedMediaType},
+		{"PatchTooLarge", "PATCH", "/mocks/1", "application/merge-patch+json", `{"name":"` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge},
+		{"PatchUnknownField", "PATCH", "/mocks/1", "application/merge-patch+json", `{"age":1}`, http.StatusUnprocessableEntity},
+		{"PatchDuplicateKey", "PATCH", "/mocks/1", "application/merge-patch+json", `{"name":"a","name":"b"}`, http.StatusBadRequest},
+		{"PatchValid", "PATCH", "/mocks/1", "application/merge-patch+json", `{"name":"a"}`, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rr := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+		})
+	}
+}
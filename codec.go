@@ -0,0 +1,251 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Codec encodes and decodes bodies of one media type.
+type Codec interface {
+	MediaType() string
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// JSONCodec is the default codec of every resource.
+var JSONCodec Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaType() string { return MIMEApplicationJSON }
+
+func (jsonCodec) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// Codecs registers additional media types for request and response bodies.
+// Requests are decoded by their Content-Type, and responses encoded in the
+// best match of Accept. A codec replaces a registered one of the same media type.
+func (r *Resource[T]) Codecs(codecs ...Codec) *Resource[T] {
+	for _, c := range codecs {
+		r.codecs = addCodec(r.codecs, c)
+	}
+	return r
+}
+
+func addCodec(codecs []Codec, c Codec) []Codec {
+	for i, existing := range codecs {
+		if existing.MediaType() == c.MediaType() {
+			codecs[i] = c
+			return codecs
+		}
+	}
+	return append(codecs, c)
+}
+
+func (b *Resource[T]) mediaTypes() []string {
+	codecs := b.allCodecs()
+	mediaTypes := make([]string, 0, len(codecs))
+	for _, c := range codecs {
+		mediaTypes = append(mediaTypes, c.MediaType())
+	}
+	return mediaTypes
+}
+
+// decode reads the request body with the codec of its Content-Type.
+// Requests without a Content-Type are decoded with the first codec,
+// unless RequireContentType is set.
+func (b *Resource[T]) decode(r *http.Request, v any) error {
+	if err := b.checkContentType(r); err != nil {
+		return err
+	}
+	codecs := b.allCodecs()
+	codec := codecs[0]
+	if contentType := r.Header.Get(HeaderContentType); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return ErrUnsupportedMediaType.Wrap(err)
+		}
+		if codec = findCodec(codecs, mediaType); codec == nil {
+			return ErrUnsupportedMediaType.WithMessage(fmt.Sprintf("unsupported media type %q, expected one of %s",
+				mediaType, strings.Join(b.mediaTypes(), ", ")))
+		}
+	}
+	if b.strictJSON && codec == JSONCodec {
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			return bodyError(err)
+		}
+		if err := unmarshalStrict(raw, v); err != nil {
+			return ErrBadRequest.Wrap(err)
+		}
+		return nil
+	}
+	if err := codec.Decode(r.Body, v); err != nil {
+		return bodyError(err)
+	}
+	return nil
+}
+
+func findCodec(codecs []Codec, mediaType string) Codec {
+	for _, c := range codecs {
+		if strings.EqualFold(c.MediaType(), mediaType) {
+			return c
+		}
+	}
+	return nil
+}
+
+// negotiate returns the codec matching the Accept header best, or nil if
+// none is acceptable. A missing header accepts the first codec.
+func negotiate(codecs []Codec, accept string) Codec {
+	if strings.TrimSpace(accept) == "" {
+		return codecs[0]
+	}
+
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	for _, r := range ranges {
+		for _, c := range codecs {
+			if matchMediaRange(r.mediaType, c.MediaType()) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+func matchMediaRange(mediaRange string, mediaType string) bool {
+	if mediaRange == "*/*" || strings.EqualFold(mediaRange, mediaType) {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(strings.ToLower(mediaType), strings.ToLower(prefix)+"/")
+}
+
+// codecWriter carries the codec negotiated for the response.
+type codecWriter struct {
+	http.ResponseWriter
+	codec Codec
+
+	// problem is set when errors are written as problem details about instance
+	problem  bool
+	instance string
+}
+
+func (w *codecWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// negotiationMiddleware picks the response codec, and rejects the request
+// with 406 when none of the codecs is acceptable.
+func (b *Resource[T]) negotiationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		problem := b.usesProblemDetails()
+		codec := negotiate(b.allCodecs(), r.Header.Get(HeaderAccept))
+		if codec == nil {
+			err := ErrNotAcceptable.WithMessage(fmt.Sprintf("none of %s is acceptable", strings.Join(b.mediaTypes(), ", ")))
+			if problem {
+				WriteError(&codecWriter{ResponseWriter: w, codec: JSONCodec, problem: true, instance: r.URL.Path}, http.StatusNotAcceptable, err)
+				return
+			}
+			JSONError(w, http.StatusNotAcceptable, err)
+			return
+		}
+		next.ServeHTTP(&codecWriter{ResponseWriter: w, codec: codec, problem: problem, instance: r.URL.Path}, r)
+	})
+}
+
+// Write encodes body with the codec negotiated for the request, or as JSON
+// when w does not come from a resource handler.
+func Write(w http.ResponseWriter, code int, body any) {
+	cw := findCodecWriter(w)
+	if cw == nil {
+		JSON(w, code, body)
+		return
+	}
+	write(w, cw.codec, code, body, cw.codec.MediaType())
+}
+
+func write(w http.ResponseWriter, codec Codec, code int, body any, mediaType string) {
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, body); err != nil {
+		JSONError(w, http.StatusInternalServerError, fmt.Errorf("failed to encode response as %s: %w", codec.MediaType(), err))
+		return
+	}
+	w.Header().Set(HeaderContentType, mediaType)
+	w.WriteHeader(code)
+	w.Write(buf.Bytes())
+}
+
+// WriteError is the codec aware version of JSONError. Resources with
+// ProblemDetails enabled write a ProblemDetails instead of an ErrorResponse.
+func WriteError(w http.ResponseWriter, code int, err error) {
+	if cw := findCodecWriter(w); cw != nil && cw.problem {
+		p := NewProblemDetails(code, err, cw.instance)
+		write(w, cw.codec, p.Status, p, problemMediaType(cw.codec.MediaType()))
+		return
+	}
+	code, res := newErrorResponse(code, err)
+	Write(w, code, res)
+}
+
+// findCodecWriter looks through the writers wrapping the one of the
+// negotiation middleware, e.g. those of middlewares recording the response.
+func findCodecWriter(w http.ResponseWriter) *codecWriter {
+	for {
+		switch t := w.(type) {
+		case *codecWriter:
+			return t
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// problemMediaType returns the problem details flavour of a media type,
+// e.g. application/problem+json for application/json.
+func problemMediaType(mediaType string) string {
+	switch {
+	case mediaType == MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		return MIMEApplicationProblemJSON
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return MIMEApplicationProblemXML
+	}
+	return mediaType
+}
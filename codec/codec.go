@@ -0,0 +1,175 @@
+// Package codec provides additional resource.Codec implementations.
+// Every codec but XML honors the json struct tags, so that the same types
+// can be served in any of the media types. XML uses encoding/xml, which
+// reads xml tags only: types served as XML need them, including xml:"-" on
+// fields hidden with json:"-", so it is not part of All.
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/invopop/yaml"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/iwanhae/resource"
+)
+
+const (
+	MIMEApplicationYAML      = "application/yaml"
+	MIMEApplicationXML       = "application/xml"
+	MIMEApplicationMsgpack   = "application/msgpack"
+	MIMEApplicationCBOR      = "application/cbor"
+	MIMEApplicationProtoJSON = "application/protobuf+json"
+)
+
+var (
+	YAML      resource.Codec = yamlCodec{}
+	XML       resource.Codec = xmlCodec{}
+	Msgpack   resource.Codec = msgpackCodec{}
+	CBOR      resource.Codec = cborCodec{}
+	ProtoJSON resource.Codec = protoJSONCodec{}
+)
+
+// All returns the codecs of this package which honor the json struct tags,
+// i.e. every codec but XML.
+func All() []resource.Codec {
+	return []resource.Codec{YAML, Msgpack, CBOR, ProtoJSON}
+}
+
+type yamlCodec struct{}
+
+func (yamlCodec) MediaType() string { return MIMEApplicationYAML }
+
+func (yamlCodec) Encode(w io.Writer, v any) error {
+	raw, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(raw)
+	return err
+}
+
+func (yamlCodec) Decode(r io.Reader, v any) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(raw, v)
+}
+
+// xmlCodec encodes with encoding/xml, so element names and hidden fields
+// follow the xml struct tags rather than the json ones.
+type xmlCodec struct{}
+
+func (xmlCodec) MediaType() string { return MIMEApplicationXML }
+
+// Encode names the root element after the type, without type parameters,
+// e.g. <ResourceList> instead of the invalid <ResourceList[main.User]>.
+func (xmlCodec) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: rootElement(v)}})
+}
+
+func (xmlCodec) Decode(r io.Reader, v any) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+func rootElement(v any) string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "response"
+	}
+	name, _, _ := strings.Cut(t.Name(), "[")
+	if name == "" {
+		return "response"
+	}
+	return name
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) MediaType() string { return MIMEApplicationMsgpack }
+
+func (msgpackCodec) Encode(w io.Writer, v any) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+func (msgpackCodec) Decode(r io.Reader, v any) error {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+type cborCodec struct{}
+
+func (cborCodec) MediaType() string { return MIMEApplicationCBOR }
+
+func (cborCodec) Encode(w io.Writer, v any) error {
+	return cbor.NewEncoder(w).Encode(v)
+}
+
+func (cborCodec) Decode(r io.Reader, v any) error {
+	return cbor.NewDecoder(r).Decode(v)
+}
+
+// protoJSONCodec uses protojson for protobuf messages, and encoding/json for
+// anything else such as list envelopes and errors.
+type protoJSONCodec struct{}
+
+func (protoJSONCodec) MediaType() string { return MIMEApplicationProtoJSON }
+
+func (protoJSONCodec) Encode(w io.Writer, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		raw, err := protojson.Marshal(m)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(raw)
+		return err
+	}
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (protoJSONCodec) Decode(r io.Reader, v any) error {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if m := protoMessage(v); m != nil {
+		return protojson.Unmarshal(raw, m)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// protoMessage returns the message v points to, allocating it if needed,
+// since resources of message type T decode into a **Message.
+func protoMessage(v any) proto.Message {
+	if m, ok := v.(proto.Message); ok {
+		return m
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Pointer {
+		return nil
+	}
+	if _, ok := rv.Elem().Interface().(proto.Message); !ok {
+		return nil
+	}
+	if rv.Elem().IsNil() {
+		rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+	}
+	return rv.Elem().Interface().(proto.Message)
+}
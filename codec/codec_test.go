@@ -0,0 +1,62 @@
+package codec_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/iwanhae/resource/codec"
+)
+
+type Sample struct {
+	ID    string   `json:"id" xml:"id"`
+	Count int      `json:"count" xml:"count"`
+	Tags  []string `json:"tags" xml:"tags"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := Sample{ID: "1", Count: 3, Tags: []string{"a", "b"}}
+	for _, c := range append(codec.All(), codec.XML) {
+		t.Run(c.MediaType(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := c.Encode(&buf, in); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			var out Sample
+			if err := c.Decode(&buf, &out); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !reflect.DeepEqual(in, out) {
+				t.Errorf("round trip mismatch: got %+v want %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestJSONTags(t *testing.T) {
+	var buf bytes.Buffer
+	if err := codec.YAML.Encode(&buf, Sample{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("id: \"1\"")) {
+		t.Errorf("expected yaml to use json tags: %s", buf.String())
+	}
+}
+
+func TestAllHonorsJSONTags(t *testing.T) {
+	type Secret struct {
+		ID     string `json:"id"`
+		Secret string `json:"-"`
+	}
+	for _, c := range codec.All() {
+		t.Run(c.MediaType(), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := c.Encode(&buf, Secret{ID: "1", Secret: "hash"}); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if bytes.Contains(buf.Bytes(), []byte("hash")) {
+				t.Errorf("expected the json:\"-\" field to be omitted: %q", buf.String())
+			}
+		})
+	}
+}
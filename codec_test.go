@@ -0,0 +1,114 @@
+package resource_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+	"github.com/iwanhae/resource/codec"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	handler := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		Codecs(codec.YAML, codec.XML).
+		Create(mockCreate).
+		Get(mockGet).
+		Handler()
+
+	testCases := []struct {
+		name                string
+		method              string
+		contentType         string
+		accept              string
+		body                string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{"Default", "GET", "", "", "", http.StatusOK, "application/json", `"name":"Test"`},
+		{"Wildcard", "GET", "", "*/*", "", http.StatusOK, "application/json", `"name":"Test"`},
+		{"YAML", "GET", "", "application/yaml", "", http.StatusOK, "application/yaml", "name: Test"},
+		{"Quality", "GET", "", "application/json;q=0.5, application/xml", "", http.StatusOK, "application/xml", "<name>Test</name>"},
+		{"TypeWildcard", "GET", "", "text/html, application/*;q=0.1", "", http.StatusOK, "application/json", `"name":"Test"`},
+		{"NotAcceptable", "GET", "", "text/html", "", http.StatusNotAcceptable, "application/json", `"code":406`},
+		{"DecodeYAML", "POST", "application/yaml", "application/json", "name: From YAML", http.StatusCreated, "application/json", `"name":"From YAML"`},
+		{"UnsupportedMediaType", "POST", "text/csv", "", "name\nx", http.StatusUnsupportedMediaType, "application/json", "unsupported media type"},
+		{"ErrorInNegotiatedType", "POST", "application/yaml", "application/yaml", "name: ''", http.StatusBadRequest, "application/yaml", "message: name is required"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := "/mocks/1"
+			if tc.method == "POST" {
+				path = "/mocks"
+			}
+			req := httptest.NewRequest(tc.method, path, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			if got := rr.Header().Get("Content-Type"); got != tc.expectedContentType {
+				t.Errorf("unexpected content type: got %v want %v", got, tc.expectedContentType)
+			}
+			if !strings.Contains(strings.ToLower(rr.Body.String()), strings.ToLower(tc.expectedBody)) {
+				t.Errorf("expected body to contain %q: %s", tc.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestFieldsInEveryCodec(t *testing.T) {
+	handler := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		Selectable("name").
+		Codecs(append(codec.All(), codec.XML)...).
+		List(mockList).
+		Get(mockGet).
+		Handler()
+
+	for _, c := range append(codec.All(), codec.XML) {
+		for _, path := range []string{"/mocks?fields=name", "/mocks/1?fields=name"} {
+			t.Run(c.MediaType()+path, func(t *testing.T) {
+				req := httptest.NewRequest("GET", path, nil)
+				req.Header.Set("Accept", c.MediaType())
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				if rr.Code != http.StatusOK {
+					t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+				}
+				if c == codec.XML {
+					if !strings.Contains(rr.Body.String(), "<name>Test</name>") {
+						t.Errorf("expected body to contain the name element: %s", rr.Body.String())
+					}
+					return
+				}
+				var got struct {
+					Name  string `json:"name"`
+					Items []struct {
+						Name string `json:"name"`
+					} `json:"items"`
+				}
+				if err := c.Decode(bytes.NewReader(rr.Body.Bytes()), &got); err != nil {
+					t.Fatalf("decode: %v", err)
+				}
+				if got.Name != "Test" && (len(got.Items) != 1 || got.Items[0].Name != "Test") {
+					t.Errorf("unexpected selection: %+v", got)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	// names are used in path wildcards, e.g. {userId}, so they must be identifiers
+	namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// plurals and subresources are path segments
+	segmentPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._~-]*$`)
+)
+
+// Validate checks the configuration of the resource and of the resources
+// nested in it. It reports every problem found, such as a missing name,
+// malformed validate tags, conflicting routes, or callbacks which are set
+// without those they depend on. RegisterMux panics with this error, before
+// registering any route.
+func (b *Resource[T]) Validate() error {
+	errs := b.validateConfig()
+	if err := checkRoutes(b.allRoutes()); err != nil {
+		errs = append(errs, b.configError(err))
+	}
+	if len(errs) == 0 {
+		if err := checkMux(b.allRoutes()); err != nil {
+			errs = append(errs, b.configError(err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateConfig checks the resource and its children, but not their routes.
+func (b *Resource[T]) validateConfig() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, b.configError(fmt.Errorf(format, args...)))
+	}
+
+	switch {
+	case b.name == "":
+		fail("missing name")
+	case !namePattern.MatchString(b.name):
+		fail("invalid name %q: it must start with a letter and contain only letters, digits and underscores", b.name)
+	}
+	switch {
+	case b.plural == "":
+		fail("missing plural")
+	case !segmentPattern.MatchString(b.plural):
+		fail("invalid plural %q: it must be a path segment of letters, digits and ._~-", b.plural)
+	}
+	if err := checkBase(b.base); err != nil {
+		fail("%w", err)
+	}
+	if b.parent != nil && b.parent.usesPathID(b.pathID()) {
+		fail("path wildcard {%s} is already used by a parent, nested resources need names of their own", b.pathID())
+	}
+
+	for name := range b.subresources {
+		switch {
+		case !segmentPattern.MatchString(name):
+			fail("invalid subresource name %q: it must be a path segment of letters, digits and ._~-", name)
+		case isOperation(name):
+			fail("subresource %q clashes with the operation of the same name", name)
+		}
+		for _, child := range b.children {
+			if child.Describe().Plural == name {
+				fail("subresource %q clashes with the nested resource of the same plural", name)
+			}
+		}
+	}
+
+	for _, err := range b.misplaced {
+		errs = append(errs, b.configError(err))
+	}
+	if err := checkTags(reflect.TypeFor[T](), map[reflect.Type]bool{}); err != nil {
+		errs = append(errs, b.configError(err))
+	}
+
+	if b.patch != nil && b.get == nil {
+		fail("Patch requires Get, which loads the item the patch is applied to")
+	}
+	if b.list != nil && b.cursorList != nil {
+		fail("List and CursorList are both set, only one of them is served")
+	}
+	if b.list == nil && b.cursorList == nil {
+		if b.count != nil {
+			fail("Count requires List")
+		}
+		if len(b.filterable) > 0 || len(b.sortable) > 0 {
+			fail("Filterable and Sortable require List or CursorList")
+		}
+	}
+	if len(b.selectable) > 0 && b.list == nil && b.cursorList == nil && b.get == nil {
+		fail("Selectable requires List, CursorList or Get")
+	}
+	if (b.batchCreate != nil || b.batchUpdate != nil || b.batchDelete != nil) && b.maxBatchSize <= 0 {
+		fail("MaxBatchSize must be positive, got %d", b.maxBatchSize)
+	}
+	if len(b.routes()) == 0 && len(b.children) == 0 {
+		fail("no operation is served, set at least one callback")
+	}
+
+	for _, child := range b.children {
+		errs = append(errs, child.validateConfig()...)
+	}
+	return errs
+}
+
+func (b *Resource[T]) configError(err error) error {
+	name := b.name
+	if name == "" {
+		name = b.plural
+	}
+	return fmt.Errorf("resource %q: %w", name, err)
+}
+
+// allRoutes returns the routes of the resource and of its nested resources.
+func (b *Resource[T]) allRoutes() []Route {
+	var routes []Route
+	var walk func(d Description)
+	walk = func(d Description) {
+		routes = append(routes, d.Routes...)
+		for _, child := range d.Nested {
+			walk(child)
+		}
+	}
+	walk(b.Describe())
+	return routes
+}
+
+func isOperation(name string) bool {
+	switch Operation(name) {
+	case OperationList, OperationCreate, OperationGet, OperationUpdate, OperationPatch, OperationDelete,
+		OperationBatchCreate, OperationBatchUpdate, OperationBatchDelete, OperationSubresource, OperationDiscovery:
+		return true
+	}
+	return false
+}
+
+// Validate checks the configuration of every resource of the group, and
+// that no route is served by two of them.
+func (g *Group) Validate() error {
+	var errs []error
+	if err := checkBase(g.base); err != nil {
+		errs = append(errs, fmt.Errorf("group %q: %w", g.base, err))
+	}
+	for _, r := range g.resources {
+		errs = append(errs, r.validateConfig()...)
+	}
+	if err := checkRoutes(g.Routes()); err != nil {
+		errs = append(errs, fmt.Errorf("group %q: %w", g.base, err))
+	}
+	if len(errs) == 0 {
+		if err := checkMux(g.Routes()); err != nil {
+			errs = append(errs, fmt.Errorf("group %q: %w", g.base, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkBase rejects base paths which are not plain paths. Wildcards are
+// rejected too, as they would overlap with the routes of other resources.
+func checkBase(base string) error {
+	switch {
+	case strings.ContainsAny(base, " \t\r\n?#"):
+		return fmt.Errorf("invalid base %q", base)
+	case strings.ContainsAny(base, "{}"):
+		return fmt.Errorf("invalid base %q: it must not contain wildcards", base)
+	}
+	return nil
+}
+
+// checkMux registers the routes on a throwaway http.ServeMux, reporting the
+// conflicts it panics with that checkRoutes missed, e.g. GET /x/y/{yId} and
+// GET /x/{xId}/q, which both match /x/y/q.
+func checkMux(routes []Route) error {
+	mux := http.NewServeMux()
+	for i, route := range routes {
+		if handles(mux, route) {
+			continue
+		}
+		for _, prev := range routes[:i] {
+			pair := http.NewServeMux()
+			if handles(pair, prev) && !handles(pair, route) {
+				return fmt.Errorf("route %q of %s conflicts with %q of %s",
+					routePattern(route), describeRoute(route), routePattern(prev), describeRoute(prev))
+			}
+		}
+		return fmt.Errorf("route %q of %s is rejected by http.ServeMux", routePattern(route), describeRoute(route))
+	}
+	return nil
+}
+
+// handles registers route on mux, reporting whether it was accepted.
+func handles(mux *http.ServeMux, route Route) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	mux.Handle(routePattern(route), http.NotFoundHandler())
+	return true
+}
+
+// mustValidate panics with the configuration errors of a resource or group.
+func mustValidate(err error) {
+	if err != nil {
+		panic(fmt.Errorf("resource: invalid configuration:\n%w", err))
+	}
+}
@@ -0,0 +1,102 @@
+package resource_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type MalformedRules struct {
+	ID    string `json:"id"`
+	Owner *struct {
+		Age int `json:"age" validate:"min=abc"`
+	} `json:"owner"`
+}
+
+func (m MalformedRules) ValidateCreate(ctx resource.Context) error            { return nil }
+func (m MalformedRules) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func TestValidate(t *testing.T) {
+	subresource := func(ctx resource.Context, w http.ResponseWriter, r *http.Request) {}
+	tags := func() *resource.Resource[MockTag] {
+		return resource.New[MockTag]().Name("tag").Plural("tags").
+			Get(func(ctx resource.Context, id string) (MockTag, error) { return MockTag{}, nil })
+	}
+
+	testCases := []struct {
+		name     string
+		resource *resource.Resource[MockResource]
+		expected []string
+	}{
+		{"Valid", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).Nest(tags()), nil},
+		{"MissingNames", resource.New[MockResource]().Get(mockGet), []string{"missing name", "missing plural"}},
+		{"InvalidName", resource.New[MockResource]().Name("my-mock").Plural("my mocks").Get(mockGet),
+			[]string{`invalid name "my-mock"`, `invalid plural "my mocks"`}},
+		{"InvalidBase", resource.New[MockResource]().Name("mock").Plural("mocks").Base("/api?v=1").Get(mockGet),
+			[]string{`invalid base "/api?v=1"`}},
+		{"NoOperation", resource.New[MockResource]().Name("mock").Plural("mocks"), []string{"no operation is served"}},
+		{"PatchWithoutGet", resource.New[MockResource]().Name("mock").Plural("mocks").Patch(mockUpdate),
+			[]string{"Patch requires Get"}},
+		{"CountWithoutList", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).Count(
+			func(ctx resource.Context, query resource.ListQuery) (int, error) { return 0, nil }),
+			[]string{"Count requires List"}},
+		{"SortableWithoutList", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).Sortable("name"),
+			[]string{"Filterable and Sortable require List"}},
+		{"SubresourceClashesWithOperation", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).
+			RegisterSubresource("delete", subresource),
+			[]string{`subresource "delete" clashes with the operation`}},
+		{"SubresourceClashesWithNested", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).
+			RegisterSubresource("tags", subresource).Nest(tags()),
+			[]string{`subresource "tags" clashes with the nested resource`}},
+		{"InvalidSubresource", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).
+			RegisterSubresource("a/b", subresource),
+			[]string{`invalid subresource name "a/b"`}},
+		{"InvalidNested", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).
+			Nest(resource.New[MockTag]().Name("tag")),
+			[]string{`resource "tag": missing plural`, `resource "tag": no operation is served`}},
+		{"DuplicateRoutes", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).
+			Nest(tags(), tags()),
+			[]string{`route "GET /mocks/{mockId}/tags/{tagId}" of operation get conflicts with "GET /mocks/{mockId}/tags/{tagId}"`}},
+		{"MalformedTag", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).
+			Nest(resource.New[MalformedRules]().Name("rule").Plural("rules").
+				Get(func(ctx resource.Context, id string) (MalformedRules, error) { return MalformedRules{}, nil })),
+			[]string{`resource "rule": invalid validate tag of field Age, rule "min=abc"`}},
+		{"NestedSameName", resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).
+			Nest(resource.New[MockResource]().Name("mock").Plural("friends").Get(mockGet)),
+			[]string{`path wildcard {mockId} is already used by a parent`}},
+		{"WildcardBase", resource.New[MockResource]().Name("mock").Plural("mocks").Base("/{tenant}").Get(mockGet),
+			[]string{`invalid base "/{tenant}": it must not contain wildcards`}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.resource.Validate()
+			if len(tc.expected) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			for _, want := range tc.expected {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("unexpected error: got %q want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRegisterMuxInvalid(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if err == nil || !strings.Contains(err.Error(), "missing plural") {
+			t.Errorf("expected RegisterMux to panic with the validation error, got %v", err)
+		}
+	}()
+	resource.New[MockResource]().Name("mock").Get(mockGet).RegisterMux(http.NewServeMux())
+}
@@ -0,0 +1,169 @@
+package resource
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// requestInfo is stored in the request context by the resource handlers,
+// and exposed through the accessors of Context.
+type requestInfo struct {
+	request   *http.Request
+	resource  string
+	operation Operation
+	requestID string
+	parentID  string
+	logger    *slog.Logger
+}
+
+type requestInfoKey struct{}
+
+func (c Context) info() *requestInfo {
+	if c.Context == nil {
+		return &requestInfo{}
+	}
+	info, _ := c.Value(requestInfoKey{}).(*requestInfo)
+	if info == nil {
+		return &requestInfo{}
+	}
+	return info
+}
+
+// RequestID returns the X-Request-ID of the request, or the one generated for it.
+func (c Context) RequestID() string {
+	return c.info().requestID
+}
+
+// Resource returns the name of the resource handling the request.
+func (c Context) Resource() string {
+	return c.info().resource
+}
+
+// Operation returns the operation being performed.
+func (c Context) Operation() Operation {
+	return c.info().operation
+}
+
+// Logger returns a logger annotated with the request ID, resource,
+// operation and principal of the request.
+func (c Context) Logger() *slog.Logger {
+	info := c.info()
+	if info.logger == nil {
+		return slog.Default()
+	}
+	if p := c.Principal(); p != nil {
+		return info.logger.With(slog.String("principal", p.Subject))
+	}
+	return info.logger
+}
+
+// PathValue returns a wildcard of the matched route, e.g. the id of a parent resource.
+func (c Context) PathValue(name string) string {
+	if r := c.info().request; r != nil {
+		return r.PathValue(name)
+	}
+	return ""
+}
+
+// Header returns the first value of the request header.
+func (c Context) Header(name string) string {
+	if r := c.info().request; r != nil {
+		return r.Header.Get(name)
+	}
+	return ""
+}
+
+func (c Context) HeaderValues(name string) []string {
+	if r := c.info().request; r != nil {
+		return r.Header.Values(name)
+	}
+	return nil
+}
+
+// HeaderInt parses the request header as an integer, or returns defaultValue when absent.
+func (c Context) HeaderInt(name string, defaultValue int) (int, error) {
+	raw := c.Header(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	val, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, ErrBadRequest.Wrap(fmt.Errorf("failed to parse integer header %q: %w", name, err))
+	}
+	return val, nil
+}
+
+// HeaderTime parses the request header as an HTTP date. It returns the zero time when absent.
+func (c Context) HeaderTime(name string) (time.Time, error) {
+	raw := c.Header(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := http.ParseTime(raw)
+	if err != nil {
+		return time.Time{}, ErrBadRequest.Wrap(fmt.Errorf("failed to parse time header %q: %w", name, err))
+	}
+	return t, nil
+}
+
+// Logger sets the logger that Context.Logger is derived from. Defaults to slog.Default().
+func (r *Resource[T]) Logger(logger *slog.Logger) *Resource[T] {
+	r.logger = logger
+	return r
+}
+
+// contextMiddleware populates the request context read by Context.
+func (b *Resource[T]) contextMiddleware(route route) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(HeaderRequestID)
+			if requestID == "" || len(requestID) > 128 {
+				requestID = newRequestID()
+			}
+			w.Header().Set(HeaderRequestID, requestID)
+
+			logger := b.logger
+			if g := b.inGroup(); logger == nil && g != nil {
+				logger = g.logger
+			}
+			if logger == nil {
+				logger = slog.Default()
+			}
+			attrs := []any{
+				slog.String("requestId", requestID),
+				slog.String("resource", b.name),
+				slog.String("operation", string(route.Operation)),
+			}
+			if id := r.PathValue(b.pathID()); id != "" {
+				attrs = append(attrs, slog.String("id", id))
+			}
+
+			info := &requestInfo{
+				resource:  b.name,
+				operation: route.Operation,
+				requestID: requestID,
+				logger:    logger.With(attrs...),
+			}
+			if b.parent != nil {
+				info.parentID = r.PathValue(b.parent.pathID())
+			}
+			r = r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info))
+			info.request = r
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("failed to generate request id: %w", err))
+	}
+	return hex.EncodeToString(b)
+}
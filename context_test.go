@@ -0,0 +1,79 @@
+package resource_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+func TestContext(t *testing.T) {
+	var logs bytes.Buffer
+	var got struct {
+		requestID string
+		resource  string
+		operation resource.Operation
+		pathValue string
+		header    string
+		limit     int
+	}
+
+	handler := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		Logger(slog.New(slog.NewTextHandler(&logs, nil))).
+		Get(func(ctx resource.Context, id string) (MockResource, error) {
+			got.requestID = ctx.RequestID()
+			got.resource = ctx.Resource()
+			got.operation = ctx.Operation()
+			got.pathValue = ctx.PathValue("mockId")
+			got.header = ctx.Header("X-Tenant")
+			got.limit, _ = ctx.HeaderInt("X-Limit", 0)
+			ctx.Logger().Info("hello")
+			return MockResource{ID: id}, nil
+		}).
+		Handler()
+
+	req := httptest.NewRequest("GET", "/mocks/42", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("X-Limit", "7")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if got.requestID != "req-1" || rr.Header().Get("X-Request-ID") != "req-1" {
+		t.Errorf("unexpected request id: %v", got.requestID)
+	}
+	if got.resource != "mock" || got.operation != resource.OperationGet {
+		t.Errorf("unexpected resource or operation: %v %v", got.resource, got.operation)
+	}
+	if got.pathValue != "42" || got.header != "acme" || got.limit != 7 {
+		t.Errorf("unexpected request values: %+v", got)
+	}
+	for _, attr := range []string{"requestId=req-1", "resource=mock", "operation=get", "id=42", "msg=hello"} {
+		if !strings.Contains(logs.String(), attr) {
+			t.Errorf("expected log to contain %q: %s", attr, logs.String())
+		}
+	}
+
+	// a generated request id is used when the client does not send one
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/mocks/42", nil))
+	if rr.Header().Get("X-Request-ID") == "" || got.requestID != rr.Header().Get("X-Request-ID") {
+		t.Errorf("expected a generated request id")
+	}
+
+	// accessors are safe outside of a request
+	ctx := resource.Context{Context: context.Background()}
+	if ctx.RequestID() != "" || ctx.Header("X-Tenant") != "" || ctx.Logger() == nil {
+		t.Errorf("unexpected values outside of a request")
+	}
+}
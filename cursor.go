@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Page is a page of a CursorList. Next and Prev are opaque to clients;
+// they are signed before leaving the server and verified when they come back.
+type Page[T Validator] struct {
+	Items []T
+	Next  string
+	Prev  string
+}
+
+// CursorList lists the resource with cursor based pagination instead of
+// offsets, and takes precedence over List.
+func (r *Resource[T]) CursorList(f CursorList[T]) *Resource[T] {
+	r.cursorList = f
+	return r
+}
+
+// CursorSecret sets the key used to sign cursors. By default a random key
+// is used, so cursors do not survive restarts or work across replicas.
+func (r *Resource[T]) CursorSecret(secret []byte) *Resource[T] {
+	r.cursorSecret = secret
+	return r
+}
+
+func (b *Resource[T]) handlerCursorList(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r)
+	query, err := b.parseListQuery(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if raw := r.URL.Query().Get("cursor"); raw != "" {
+		if query.Cursor, err = decodeCursor(b.cursorSecret, raw); err != nil {
+			WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	page, err := b.cursorList(ctx, query)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	hasMore := page.Next != ""
+	metadata := Metadata{
+		Limit:      query.Limit,
+		HasMore:    &hasMore,
+		NextCursor: encodeCursor(b.cursorSecret, page.Next),
+		PrevCursor: encodeCursor(b.cursorSecret, page.Prev),
+	}
+	if b.count != nil {
+		total, err := b.count(ctx, query)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		metadata.Total = &total
+	}
+	setCursorLinks(w, r, metadata)
+	writeList(w, page.Items, query.Fields, metadata)
+}
+
+// encodeCursor returns base64(cursor) + "." + base64(hmac(cursor)).
+func encodeCursor(secret []byte, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(cursor)) + "." + enc.EncodeToString(signCursor(secret, []byte(cursor)))
+}
+
+func decodeCursor(secret []byte, token string) (string, error) {
+	enc := base64.RawURLEncoding
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", fmt.Errorf("malformed cursor")
+	}
+	cursor, err := enc.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("malformed cursor: %w", err)
+	}
+	mac, err := enc.DecodeString(signature)
+	if err != nil {
+		return "", fmt.Errorf("malformed cursor: %w", err)
+	}
+	if !hmac.Equal(mac, signCursor(secret, cursor)) {
+		return "", fmt.Errorf("invalid cursor")
+	}
+	return string(cursor), nil
+}
+
+func signCursor(secret []byte, cursor []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(cursor)
+	return h.Sum(nil)
+}
+
+func randomSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Errorf("failed to generate secret: %w", err))
+	}
+	return secret
+}
@@ -0,0 +1,80 @@
+package resource_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+func TestCursorList(t *testing.T) {
+	items := []MockResource{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}}
+
+	r := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		CursorSecret([]byte("secret")).
+		CursorList(func(ctx resource.Context, query resource.ListQuery) (resource.Page[MockResource], error) {
+			start := 0
+			if query.Cursor != "" {
+				start, _ = strconv.Atoi(query.Cursor)
+			}
+			end := min(start+query.Limit, len(items))
+			page := resource.Page[MockResource]{Items: items[start:end]}
+			if end < len(items) {
+				page.Next = strconv.Itoa(end)
+			}
+			if start > 0 {
+				page.Prev = strconv.Itoa(max(start-query.Limit, 0))
+			}
+			return page, nil
+		})
+	handler := r.Handler()
+
+	list := func(cursor string) (int, resource.ResourceList[MockResource]) {
+		req := httptest.NewRequest("GET", "/mocks?limit=2&cursor="+url.QueryEscape(cursor), nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		var result resource.ResourceList[MockResource]
+		json.NewDecoder(rr.Body).Decode(&result)
+		return rr.Code, result
+	}
+
+	var ids []string
+	cursor := ""
+	for {
+		code, page := list(cursor)
+		if code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", code, http.StatusOK)
+		}
+		for _, item := range page.Items {
+			ids = append(ids, item.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if page.NextCursor == "2" || page.NextCursor == "4" {
+			t.Fatalf("cursor is not signed: %v", page.NextCursor)
+		}
+		cursor = page.NextCursor
+	}
+	if len(ids) != len(items) {
+		t.Errorf("unexpected items: got %v", ids)
+	}
+
+	_, last := list(cursor)
+	if last.PrevCursor == "" {
+		t.Errorf("expected a previous cursor on the last page")
+	}
+
+	for _, forged := range []string{"NA", "NA.AAAA", "garbage"} {
+		if code, _ := list(forged); code != http.StatusBadRequest {
+			t.Errorf("forged cursor %q: got %v want %v", forged, code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package resource
+
+import (
+	"reflect"
+)
+
+type Operation string
+
+const (
+	OperationList        Operation = "list"
+	OperationCreate      Operation = "create"
+	OperationGet         Operation = "get"
+	OperationUpdate      Operation = "update"
+	OperationPatch       Operation = "patch"
+	OperationDelete      Operation = "delete"
+	OperationSubresource Operation = "subresource"
+)
+
+// Route is a single endpoint served by a resource.
+// Method is empty for subresources, which accept any method.
+type Route struct {
+	Method      string
+	Path        string
+	Operation   Operation
+	Subresource string
+}
+
+// Description is a type-erased view of a Resource, used to generate
+// documents such as OpenAPI specs.
+type Description struct {
+	Name   string
+	Plural string
+	Base   string
+	PathID string
+	Type   reflect.Type
+	Routes []Route
+
+	// Parent is the name of the resource this one is nested in, and Nested
+	// describes the resources nested in this one
+	Parent string
+	Nested []Description
+
+	// Versioned is set when the resource implements Versioner
+	Versioned bool
+
+	// MediaTypes of the request and response bodies, the first one being the default
+	MediaTypes []string
+
+	// MaxBodySize limits request bodies, zero meaning no limit
+	MaxBodySize int64
+
+	// ProblemDetails is set when errors are reported as RFC 9457 problem details
+	ProblemDetails bool
+
+	// Security lists the schemes of the authenticators, any of which is accepted
+	Security []SecurityScheme
+	// Authorized is set when an Authorizer may reject requests with 403
+	Authorized bool
+
+	// Cursor is set when the resource is listed with CursorList
+	Cursor bool
+	// MaxLimit caps the limit of list requests, zero meaning no cap
+	MaxLimit int
+
+	// PartialBatches is set when batches may succeed for some items only
+	PartialBatches bool
+
+	Filterable []string
+	Sortable   []string
+	Selectable []string
+}
+
+type Describer interface {
+	Describe() Description
+}
+
+func (b *Resource[T]) Describe() Description {
+	d := Description{
+		Name:   b.name,
+		Plural: b.plural,
+		Base:   b.basePath(),
+		PathID: b.pathID(),
+		Type:   reflect.TypeFor[T](),
+
+		MediaTypes: b.mediaTypes(),
+		Versioned:  isVersioned[T](),
+
+		MaxBodySize:    b.maxBodySize,
+		ProblemDetails: b.usesProblemDetails(),
+		Authorized:     b.authorizes(),
+		Cursor:         b.cursorList != nil,
+		MaxLimit:       b.maxLimit,
+		PartialBatches: b.partialBatches,
+		Filterable:     b.filterable,
+		Sortable:       b.sortable,
+		Selectable:     b.selectable,
+	}
+	for _, a := range b.allAuthenticators() {
+		d.Security = append(d.Security, a.SecurityScheme())
+	}
+	for _, route := range b.routes() {
+		d.Routes = append(d.Routes, route.Route)
+	}
+	if b.parent != nil {
+		d.Parent = b.parent.describeName()
+	}
+	for _, child := range b.children {
+		d.Nested = append(d.Nested, child.Describe())
+	}
+	return d
+}
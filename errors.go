@@ -0,0 +1,109 @@
+package resource
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error is an error which knows the HTTP status code it should be reported with.
+// Callbacks may return it directly, or wrap one of the sentinels below with
+// fmt.Errorf("...: %w", resource.ErrNotFound).
+type Error struct {
+	Status  int
+	Reason  string
+	Message string
+	Err     error
+
+	// Type and Extensions are only reported in problem details.
+	Type       string
+	Extensions map[string]any
+}
+
+var (
+	ErrBadRequest           = NewError(http.StatusBadRequest, "BadRequest", "")
+	ErrUnauthorized         = NewError(http.StatusUnauthorized, "Unauthorized", "")
+	ErrForbidden            = NewError(http.StatusForbidden, "Forbidden", "")
+	ErrNotFound             = NewError(http.StatusNotFound, "NotFound", "")
+	ErrConflict             = NewError(http.StatusConflict, "Conflict", "")
+	ErrNotAcceptable        = NewError(http.StatusNotAcceptable, "NotAcceptable", "")
+	ErrPreconditionFailed   = NewError(http.StatusPreconditionFailed, "PreconditionFailed", "")
+	ErrPayloadTooLarge      = NewError(http.StatusRequestEntityTooLarge, "PayloadTooLarge", "")
+	ErrUnsupportedMediaType = NewError(http.StatusUnsupportedMediaType, "UnsupportedMediaType", "")
+	ErrUnprocessable        = NewError(http.StatusUnprocessableEntity, "Unprocessable", "")
+	ErrTooManyRequests      = NewError(http.StatusTooManyRequests, "TooManyRequests", "")
+	ErrInternal             = NewError(http.StatusInternalServerError, "Internal", "")
+)
+
+// StatusCoder can be implemented by custom errors to choose the status code
+// used by JSONError.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+func NewError(status int, reason string, message string) *Error {
+	return &Error{
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) StatusCode() int {
+	return e.Status
+}
+
+// Is reports errors with the same status and reason as equal, so that
+// errors.Is(err, ErrNotFound) holds for any not found error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Status == t.Status && e.Reason == t.Reason
+}
+
+// WithMessage returns a copy of e with the given message.
+func (e *Error) WithMessage(message string) *Error {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+// Wrap returns a copy of e which wraps err.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// statusFromError returns the status code carried by err, or fallback if none.
+func statusFromError(err error, fallback int) int {
+	var sc StatusCoder
+	if errors.As(err, &sc) && sc.StatusCode() != 0 {
+		return sc.StatusCode()
+	}
+	return fallback
+}
+
+func reasonFromError(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Reason
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package resource_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type teapotError struct{}
+
+func (teapotError) Error() string   { return "short and stout" }
+func (teapotError) StatusCode() int { return http.StatusTeapot }
+
+func TestErrorStatus(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedReason string
+	}{
+		{"Plain", errors.New("boom"), http.StatusInternalServerError, ""},
+		{"Sentinel", resource.ErrNotFound, http.StatusNotFound, "NotFound"},
+		{"Wrapped", fmt.Errorf("mock %q: %w", "1", resource.ErrConflict), http.StatusConflict, "Conflict"},
+		{"Custom", resource.NewError(http.StatusTooManyRequests, "SlowDown", "too fast"), http.StatusTooManyRequests, "SlowDown"},
+		{"StatusCoder", teapotError{}, http.StatusTeapot, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := resource.New[MockResource]().
+				Name("mock").
+				Plural("mocks").
+				Get(func(ctx resource.Context, id string) (MockResource, error) {
+					return MockResource{}, tc.err
+				})
+
+			req := httptest.NewRequest("GET", "/mocks/1", nil)
+			rr := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					rr.Code, tc.expectedStatus)
+			}
+
+			var errResp resource.ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+				t.Fatalf("Could not decode error response: %v", err)
+			}
+			if errResp.Code != tc.expectedStatus || errResp.Reason != tc.expectedReason {
+				t.Errorf("Unexpected error response: got %+v", errResp)
+			}
+		})
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	err := fmt.Errorf("lookup: %w", resource.ErrNotFound.WithMessage("mock not found"))
+	if !errors.Is(err, resource.ErrNotFound) {
+		t.Errorf("expected %v to match ErrNotFound", err)
+	}
+	if errors.Is(err, resource.ErrConflict) {
+		t.Errorf("expected %v not to match ErrConflict", err)
+	}
+}
@@ -0,0 +1,118 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Versioner can be implemented by resources to enable optimistic concurrency.
+// The version is sent as the ETag of responses, and PUT, PATCH and DELETE
+// requests with a mismatching If-Match header are rejected with 412.
+//
+// The handlers compare If-Match with the item returned by Get before calling
+// Update, Patch or Delete, which is only best effort: another write may land
+// in between. To prevent lost updates, the callbacks call
+// Context.CheckIfMatch with the stored item atomically with the write, as
+// memstore and sqlstore do.
+type Versioner interface {
+	Version() string
+}
+
+func etagOf(v any) string {
+	versioner, ok := v.(Versioner)
+	if !ok {
+		return ""
+	}
+	version := versioner.Version()
+	if version == "" {
+		return ""
+	}
+	return fmt.Sprintf("%q", version)
+}
+
+func setETag(w http.ResponseWriter, v any) {
+	if etag := etagOf(v); etag != "" {
+		w.Header().Set(HeaderETag, etag)
+	}
+}
+
+// IfMatch returns the If-Match header of the request, which is set when the
+// write must only be applied to a given version of the item.
+func (c Context) IfMatch() string {
+	return c.Header(HeaderIfMatch)
+}
+
+// CheckIfMatch compares the If-Match header of the request with the version
+// of current, the stored item about to be written. It fails with
+// ErrPreconditionFailed when they differ, and is nil for unconditional
+// requests and items which are not Versioners.
+func (c Context) CheckIfMatch(current any) error {
+	if r := c.info().request; r != nil {
+		return ifMatch(r, current)
+	}
+	return nil
+}
+
+// checkIfMatch loads the current resource to compare it against If-Match,
+// only when the header is present and T is versioned. It is a best effort
+// check, see Versioner.
+func (b *Resource[T]) checkIfMatch(ctx Context, r *http.Request, id string) error {
+	if r.Header.Get(HeaderIfMatch) == "" || b.get == nil {
+		return nil
+	}
+	if !isVersioned[T]() {
+		return nil
+	}
+	current, err := b.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return ifMatch(r, current)
+}
+
+func isVersioned[T any]() bool {
+	return reflect.TypeFor[T]().Implements(reflect.TypeFor[Versioner]())
+}
+
+func ifMatch(r *http.Request, current any) error {
+	header := r.Header.Get(HeaderIfMatch)
+	if header == "" {
+		return nil
+	}
+	etag := etagOf(current)
+	if etag == "" {
+		return nil
+	}
+	if !matchETag(header, etag, false) {
+		return ErrPreconditionFailed.WithMessage(fmt.Sprintf("resource has been modified, current version is %s", etag))
+	}
+	return nil
+}
+
+func notModified(r *http.Request, etag string) bool {
+	header := r.Header.Get(HeaderIfNoneMatch)
+	return header != "" && etag != "" && matchETag(header, etag, true)
+}
+
+// matchETag reports whether etag is listed in header. If-Match uses the strong
+// comparison, where weak tags never match, and If-None-Match the weak one.
+func matchETag(header string, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
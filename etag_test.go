@@ -0,0 +1,79 @@
+package resource_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type VersionedResource struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Revision string `json:"revision"`
+}
+
+func (v VersionedResource) ValidateCreate(ctx resource.Context) error            { return nil }
+func (v VersionedResource) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+func (v VersionedResource) Version() string                                      { return v.Revision }
+
+func TestETag(t *testing.T) {
+	get := func(ctx resource.Context, id string) (VersionedResource, error) {
+		return VersionedResource{ID: id, Name: "Test", Revision: "3"}, nil
+	}
+	save := func(ctx resource.Context, id string, v VersionedResource) (VersionedResource, error) {
+		v.Revision = "4"
+		return v, nil
+	}
+	handler := resource.New[VersionedResource]().
+		Name("mock").
+		Plural("mocks").
+		Get(get).
+		Update(save).
+		Patch(save).
+		Delete(func(ctx resource.Context, id string) error { return nil }).
+		Handler()
+
+	testCases := []struct {
+		name           string
+		method         string
+		header         string
+		value          string
+		expectedStatus int
+		expectedETag   string
+	}{
+		{"Get", "GET", "", "", http.StatusOK, `"3"`},
+		{"GetNotModified", "GET", "If-None-Match", `"3"`, http.StatusNotModified, `"3"`},
+		{"GetWeakNotModified", "GET", "If-None-Match", `"1", W/"3"`, http.StatusNotModified, `"3"`},
+		{"GetModified", "GET", "If-None-Match", `"2"`, http.StatusOK, `"3"`},
+		{"Update", "PUT", "If-Match", `"3"`, http.StatusOK, `"4"`},
+		{"UpdateWildcard", "PUT", "If-Match", `*`, http.StatusOK, `"4"`},
+		{"UpdateStale", "PUT", "If-Match", `"2"`, http.StatusPreconditionFailed, ""},
+		{"UpdateWeak", "PUT", "If-Match", `W/"3"`, http.StatusPreconditionFailed, ""},
+		{"UpdateUnconditional", "PUT", "", "", http.StatusOK, `"4"`},
+		{"Patch", "PATCH", "If-Match", `"3"`, http.StatusOK, `"4"`},
+		{"PatchStale", "PATCH", "If-Match", `"2"`, http.StatusPreconditionFailed, ""},
+		{"Delete", "DELETE", "If-Match", `"3"`, http.StatusNoContent, ""},
+		{"DeleteStale", "DELETE", "If-Match", `"2"`, http.StatusPreconditionFailed, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/mocks/1", strings.NewReader(`{"name":"Updated"}`))
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if got := rr.Header().Get("ETag"); got != tc.expectedETag {
+				t.Errorf("unexpected ETag: got %v want %v", got, tc.expectedETag)
+			}
+		})
+	}
+}
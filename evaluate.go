@@ -0,0 +1,178 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ApplyListQuery filters, sorts and pages items in memory as requested by q,
+// for stores without a query language of their own. Fields are compared by
+// their JSON names and values. It returns the page, and the number of items
+// matching the filters.
+func ApplyListQuery[T any](items []T, q ListQuery) ([]T, int, error) {
+	type entry struct {
+		item T
+		doc  map[string]any
+	}
+	matched := make([]entry, 0, len(items))
+	for _, item := range items {
+		doc, err := jsonDocument(item)
+		if err != nil {
+			return nil, 0, err
+		}
+		ok, err := matchFilters(doc, q.Filters)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			matched = append(matched, entry{item, doc})
+		}
+	}
+
+	if len(q.Sort) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			for _, key := range q.Sort {
+				c := compareValues(matched[i].doc[key.Field], matched[j].doc[key.Field])
+				if c == 0 {
+					continue
+				}
+				if key.Desc {
+					return c > 0
+				}
+				return c < 0
+			}
+			return false
+		})
+	}
+
+	total := len(matched)
+	start := min(max(q.Offset, 0), total)
+	end := total
+	if q.Limit > 0 {
+		end = min(start+q.Limit, total)
+	}
+	page := make([]T, 0, end-start)
+	for _, e := range matched[start:end] {
+		page = append(page, e.item)
+	}
+	return page, total, nil
+}
+
+func jsonDocument(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func matchFilters(doc map[string]any, filters []Filter) (bool, error) {
+	for _, f := range filters {
+		ok, err := matchFilter(doc[f.Field], f)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchFilter(value any, f Filter) (bool, error) {
+	if f.Operator == FilterContains {
+		switch v := value.(type) {
+		case string:
+			return strings.Contains(v, f.Value), nil
+		case []any:
+			for _, elem := range v {
+				if fmt.Sprint(elem) == f.Value {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+
+	var c int
+	switch v := value.(type) {
+	case json.Number:
+		if _, err := strconv.ParseFloat(f.Value, 64); err != nil {
+			return false, ErrBadRequest.WithMessage(fmt.Sprintf("invalid number %q for field %q", f.Value, f.Field))
+		}
+		c = compareValues(v, json.Number(f.Value))
+	case bool:
+		b, err := strconv.ParseBool(f.Value)
+		if err != nil {
+			return false, ErrBadRequest.WithMessage(fmt.Sprintf("invalid boolean %q for field %q", f.Value, f.Field))
+		}
+		c = compareValues(v, b)
+	case nil:
+		if f.Value != "null" {
+			return f.Operator == FilterNotEqual, nil
+		}
+	default:
+		c = strings.Compare(fmt.Sprint(v), f.Value)
+	}
+
+	switch f.Operator {
+	case FilterEqual:
+		return c == 0, nil
+	case FilterNotEqual:
+		return c != 0, nil
+	case FilterGreater:
+		return c > 0, nil
+	case FilterGreaterOrEqual:
+		return c >= 0, nil
+	case FilterLess:
+		return c < 0, nil
+	case FilterLessOrEqual:
+		return c <= 0, nil
+	}
+	return false, ErrBadRequest.WithMessage(fmt.Sprintf("unsupported operator %q", f.Operator))
+}
+
+// compareValues orders decoded JSON values. Null sorts first, and values of
+// different types are compared by their string form.
+func compareValues(a any, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch a := a.(type) {
+	case json.Number:
+		if b, ok := b.(json.Number); ok {
+			x, _ := a.Float64()
+			y, _ := b.Float64()
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			switch {
+			case a == b:
+				return 0
+			case !a:
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
@@ -0,0 +1,61 @@
+package resource_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type Book struct {
+	Title  string   `json:"title"`
+	Pages  int      `json:"pages"`
+	Public bool     `json:"public"`
+	Tags   []string `json:"tags"`
+}
+
+func TestApplyListQuery(t *testing.T) {
+	books := []Book{
+		{"Go", 300, true, []string{"programming"}},
+		{"Rust", 500, false, []string{"programming"}},
+		{"Poems", 80, true, []string{"poetry"}},
+		{"Gophers", 120, true, nil},
+	}
+
+	testCases := []struct {
+		name          string
+		query         resource.ListQuery
+		expected      []string
+		expectedTotal int
+	}{
+		{"All", resource.ListQuery{}, []string{"Go", "Rust", "Poems", "Gophers"}, 4},
+		{"Number", resource.ListQuery{Filters: []resource.Filter{{Field: "pages", Operator: resource.FilterGreater, Value: "100"}}}, []string{"Go", "Rust", "Gophers"}, 3},
+		{"Bool", resource.ListQuery{Filters: []resource.Filter{{Field: "public", Operator: resource.FilterEqual, Value: "false"}}}, []string{"Rust"}, 1},
+		{"Contains", resource.ListQuery{Filters: []resource.Filter{{Field: "title", Operator: resource.FilterContains, Value: "Go"}}}, []string{"Go", "Gophers"}, 2},
+		{"ContainsElement", resource.ListQuery{Filters: []resource.Filter{{Field: "tags", Operator: resource.FilterContains, Value: "poetry"}}}, []string{"Poems"}, 1},
+		{"SortDesc", resource.ListQuery{Sort: []resource.SortKey{{Field: "pages", Desc: true}}}, []string{"Rust", "Go", "Gophers", "Poems"}, 4},
+		{"SortThenPage", resource.ListQuery{Sort: []resource.SortKey{{Field: "public"}, {Field: "title"}}, Offset: 1, Limit: 2}, []string{"Go", "Gophers"}, 4},
+		{"OffsetPastEnd", resource.ListQuery{Offset: 10, Limit: 2}, []string{}, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, total, err := resource.ApplyListQuery(books, tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			titles := []string{}
+			for _, b := range page {
+				titles = append(titles, b.Title)
+			}
+			if fmt.Sprint(titles) != fmt.Sprint(tc.expected) || total != tc.expectedTotal {
+				t.Errorf("Expect %v (%d) but got %v (%d)", tc.expected, tc.expectedTotal, titles, total)
+			}
+		})
+	}
+
+	_, _, err := resource.ApplyListQuery(books, resource.ListQuery{Filters: []resource.Filter{{Field: "pages", Operator: resource.FilterEqual, Value: "many"}}})
+	if err == nil {
+		t.Errorf("expected an error for a non numeric filter on a number")
+	}
+}
@@ -0,0 +1,108 @@
+// Package filestore implements the callbacks of a resource on top of a
+// local JSON file, for small services that do not justify a database:
+//
+//	store, err := filestore.Open("users.json", func(u *User) *string { return &u.ID })
+//	if err != nil {
+//		return err
+//	}
+//	store.Bind(users)
+//
+// The whole collection is kept in a memstore.Store and the file is rewritten
+// on every change. A change is written to a temporary file which then
+// replaces the previous one, so the file always holds either the old or the
+// new content.
+package filestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/iwanhae/resource"
+	"github.com/iwanhae/resource/memstore"
+)
+
+// Store is a memstore.Store which saves its items to a file. Changes which
+// cannot be saved are not applied.
+type Store[T resource.Validator] struct {
+	*memstore.Store[T]
+	path string
+}
+
+// state is the content of the file.
+type state[T resource.Validator] struct {
+	NextID int `json:"nextId"`
+	Items  []T `json:"items"`
+}
+
+// Open loads the store saved at path, or returns an empty one when the file
+// does not exist yet. id returns a pointer to the ID field of an item.
+func Open[T resource.Validator](path string, id func(item *T) *string) (*Store[T], error) {
+	var st state[T]
+	raw, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(raw, &st); err != nil {
+			return nil, fmt.Errorf("filestore: decode %s: %w", path, err)
+		}
+	}
+	s := &Store[T]{Store: memstore.New(id).Load(st.Items, st.NextID), path: path}
+	s.OnChange(func(items []T, lastID int) error {
+		if err := s.save(state[T]{NextID: lastID, Items: items}); err != nil {
+			return resource.ErrInternal.WithMessage("failed to save the store").Wrap(err)
+		}
+		return nil
+	})
+	return s, nil
+}
+
+// save writes st to a temporary file in the same directory, which is then
+// renamed over the store file. The rename commits the change, so a failure
+// to sync the directory afterwards, which only risks losing the change on a
+// crash, is logged rather than returned.
+func (s *Store[T]) save(st state[T]) error {
+	raw, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		return err
+	}
+	if err := syncDir(dir); err != nil {
+		slog.Warn("filestore: failed to sync directory", "dir", dir, "error", err)
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
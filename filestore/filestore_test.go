@@ -0,0 +1,126 @@
+package filestore_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+	"github.com/iwanhae/resource/filestore"
+)
+
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (u User) ValidateCreate(ctx resource.Context) error            { return nil }
+func (u User) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func openUsers(t *testing.T, path string) http.Handler {
+	store, err := filestore.Open(path, func(u *User) *string { return &u.ID })
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := resource.New[User]().
+		Name("user").
+		Plural("users").
+		Filterable("name", "age").
+		Sortable("age")
+	return store.Bind(users).Handler()
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	testCases := []struct {
+		name           string
+		reopen         bool
+		method         string
+		target         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"CreateAlice", false, "POST", "/users", `{"name":"alice","age":30}`, http.StatusCreated, `{"id":"1","name":"alice","age":30}`},
+		{"CreateBob", false, "POST", "/users", `{"name":"bob","age":20}`, http.StatusCreated, `{"id":"2","name":"bob","age":20}`},
+		{"CreateConflict", false, "POST", "/users", `{"id":"2","name":"bob"}`, http.StatusConflict, ""},
+		{"GetAfterRestart", true, "GET", "/users/1", "", http.StatusOK, `{"id":"1","name":"alice","age":30}`},
+		{"Update", false, "PUT", "/users/2", `{"name":"bobby","age":21}`, http.StatusOK, `{"id":"2","name":"bobby","age":21}`},
+		{"UpdateNotFound", false, "PUT", "/users/9", `{"name":"x"}`, http.StatusNotFound, ""},
+		{"ListAfterRestart", true, "GET", "/users?sort=-age", "", http.StatusOK,
+			`{"items":[{"id":"1","name":"alice","age":30},{"id":"2","name":"bobby","age":21}],"metadata":{"offset":0,"limit":10,"total":2,"hasMore":false}}`},
+		{"Delete", false, "DELETE", "/users/2", "", http.StatusNoContent, ""},
+		{"GetDeleted", true, "GET", "/users/2", "", http.StatusNotFound, ""},
+		{"IDNotReused", true, "POST", "/users", `{"name":"carol","age":40}`, http.StatusCreated, `{"id":"3","name":"carol","age":40}`},
+	}
+
+	handler := openUsers(t, path)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.reopen {
+				handler = openUsers(t, path)
+			}
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			if tc.expectedBody == "" {
+				return
+			}
+			var got, expected any
+			json.Unmarshal(rr.Body.Bytes(), &got)
+			json.Unmarshal([]byte(tc.expectedBody), &expected)
+			gotJSON, _ := json.Marshal(got)
+			expectedJSON, _ := json.Marshal(expected)
+			if string(gotJSON) != string(expectedJSON) {
+				t.Errorf("handler returned unexpected body: got %s want %s", gotJSON, expectedJSON)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the store file to remain, got %d entries", len(entries))
+	}
+}
+
+func TestOpenCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := filestore.Open(path, func(u *User) *string { return &u.ID }); err == nil {
+		t.Errorf("expected an error for a corrupted file")
+	}
+}
+
+func TestSaveFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	store, err := filestore.Open(filepath.Join(dir, "users.json"), func(u *User) *string { return &u.ID })
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := resource.Context{}
+	if _, err := store.Create(ctx, User{Name: "alice"}); err == nil {
+		t.Fatalf("expected an error when the file cannot be written")
+	}
+	if _, err := store.Get(ctx, "1"); err == nil {
+		t.Errorf("expected the failed create not to be kept")
+	}
+}
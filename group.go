@@ -0,0 +1,275 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// OperationDiscovery is the operation of the discovery endpoint of a Group.
+const OperationDiscovery Operation = "discovery"
+
+// Registrar is implemented by Resource, regardless of its type parameter.
+type Registrar interface {
+	Describer
+	register(mux *http.ServeMux)
+	setParent(p parent)
+	setGroup(g *Group)
+	validateConfig() []error
+}
+
+// Group mounts resources of any type under a shared prefix, such as an API
+// version. The same type can be registered in several groups with different
+// handlers:
+//
+//	v1 := resource.NewGroup("/api/v1").Add(usersV1)
+//	v2 := resource.NewGroup("/api/v2").Add(usersV2, orders).Use(logRequests)
+//
+// The middlewares, codecs, logger and error format of the group apply to
+// every resource in it, and to the resources nested in them.
+type Group struct {
+	base      string
+	resources []Registrar
+
+	middlewares    []Middleware
+	codecs         []Codec
+	logger         *slog.Logger
+	problemDetails bool
+	discovery      bool
+}
+
+func NewGroup(base string) *Group {
+	return &Group{
+		base: cleanBase(base),
+	}
+}
+
+// Add prefixes each resource's base path with the group's base path.
+// A resource must belong to only one group, and not be nested in another
+// resource, which Validate reports.
+func (g *Group) Add(resources ...Registrar) *Group {
+	for _, r := range resources {
+		r.setGroup(g)
+		g.resources = append(g.resources, r)
+	}
+	return g
+}
+
+// Use adds middlewares to every operation of the resources of the group.
+// They run before the middlewares of the resources themselves.
+func (g *Group) Use(middlewares ...Middleware) *Group {
+	g.middlewares = append(g.middlewares, middlewares...)
+	return g
+}
+
+// Codecs registers media types for the bodies of every resource of the
+// group. A codec registered on a resource takes precedence over one of the
+// same media type registered on the group.
+func (g *Group) Codecs(codecs ...Codec) *Group {
+	for _, c := range codecs {
+		g.codecs = addCodec(g.codecs, c)
+	}
+	return g
+}
+
+// Logger sets the logger of the resources which do not have their own.
+func (g *Group) Logger(logger *slog.Logger) *Group {
+	g.logger = logger
+	return g
+}
+
+// ProblemDetails reports the errors of every resource of the group as
+// RFC 9457 problem details.
+func (g *Group) ProblemDetails(enabled bool) *Group {
+	g.problemDetails = enabled
+	return g
+}
+
+// Discovery serves GET {base}/ with an APIResourceList of the resources
+// of the group.
+func (g *Group) Discovery(enabled bool) *Group {
+	g.discovery = enabled
+	return g
+}
+
+// Resources returns the resources of the group, e.g. to build an OpenAPI document.
+func (g *Group) Resources() []Describer {
+	describers := make([]Describer, 0, len(g.resources))
+	for _, r := range g.resources {
+		describers = append(describers, r)
+	}
+	return describers
+}
+
+// Routes returns every route served by the group, including those of
+// nested resources and the discovery endpoint.
+func (g *Group) Routes() []Route {
+	var routes []Route
+	var walk func(d Description)
+	walk = func(d Description) {
+		routes = append(routes, d.Routes...)
+		for _, child := range d.Nested {
+			walk(child)
+		}
+	}
+	for _, r := range g.resources {
+		walk(r.Describe())
+	}
+	if g.discovery {
+		routes = append(routes, Route{http.MethodGet, g.base + "/{$}", OperationDiscovery, ""})
+	}
+	return routes
+}
+
+// RegisterMux registers every route of the group. It panics with the
+// error of Validate, before registering any route.
+func (g *Group) RegisterMux(mux *http.ServeMux) *Group {
+	mustValidate(g.Validate())
+	for _, r := range g.resources {
+		r.register(mux)
+	}
+	if g.discovery {
+		mux.Handle(fmt.Sprintf("GET %s/{$}", g.base), chain(http.HandlerFunc(g.handlerDiscovery), g.middlewares...))
+	}
+	return g
+}
+
+func (g *Group) Handler() http.Handler {
+	mux := http.NewServeMux()
+	g.RegisterMux(mux)
+	return mux
+}
+
+// APIResourceList is the response of the discovery endpoint of a Group.
+type APIResourceList struct {
+	Base      string        `json:"base"`
+	Resources []APIResource `json:"resources"`
+}
+
+// APIResource describes a resource of a Group, and the operations it supports.
+type APIResource struct {
+	Name         string      `json:"name"`
+	Plural       string      `json:"plural"`
+	Path         string      `json:"path"`
+	Parent       string      `json:"parent,omitempty"`
+	Verbs        []Operation `json:"verbs"`
+	Subresources []string    `json:"subresources,omitempty"`
+	MediaTypes   []string    `json:"mediaTypes"`
+}
+
+// Discover lists the resources of the group, nested ones following their parent.
+func (g *Group) Discover() APIResourceList {
+	list := APIResourceList{Base: g.base, Resources: []APIResource{}}
+	var walk func(d Description)
+	walk = func(d Description) {
+		res := APIResource{
+			Name:       d.Name,
+			Plural:     d.Plural,
+			Path:       d.Base + "/" + d.Plural,
+			Parent:     d.Parent,
+			Verbs:      []Operation{},
+			MediaTypes: d.MediaTypes,
+		}
+		for _, route := range d.Routes {
+			if route.Operation == OperationSubresource {
+				res.Subresources = append(res.Subresources, route.Subresource)
+				continue
+			}
+			res.Verbs = append(res.Verbs, route.Operation)
+		}
+		list.Resources = append(list.Resources, res)
+		for _, child := range d.Nested {
+			walk(child)
+		}
+	}
+	for _, r := range g.resources {
+		walk(r.Describe())
+	}
+	return list
+}
+
+func (g *Group) handlerDiscovery(w http.ResponseWriter, r *http.Request) {
+	Write(w, http.StatusOK, g.Discover())
+}
+
+var wildcardPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// checkRoutes reports the routes which http.ServeMux would reject as
+// duplicates, i.e. those with the same method and path once wildcards are
+// ignored.
+func checkRoutes(routes []Route) error {
+	seen := make(map[string]Route, len(routes))
+	var errs []error
+	for _, route := range routes {
+		key := route.Method + " " + wildcardPattern.ReplaceAllStringFunc(route.Path, func(w string) string {
+			if w == "{$}" {
+				return w
+			}
+			return "{}"
+		})
+		if prev, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("route %q of %s conflicts with %q of %s",
+				routePattern(route), describeRoute(route), routePattern(prev), describeRoute(prev)))
+			continue
+		}
+		seen[key] = route
+	}
+	return errors.Join(errs...)
+}
+
+func routePattern(route Route) string {
+	return strings.TrimSpace(route.Method + " " + route.Path)
+}
+
+func describeRoute(route Route) string {
+	if route.Operation == OperationSubresource {
+		return "subresource " + route.Subresource
+	}
+	return "operation " + string(route.Operation)
+}
+
+// setGroup keeps the first group or parent of the resource, recording the
+// others as misplacements reported by Validate.
+func (b *Resource[T]) setGroup(g *Group) {
+	switch {
+	case b.group != nil:
+		b.misplaced = append(b.misplaced, fmt.Errorf("added to group %q, but it is already in group %q", g.base, b.group.base))
+	case b.parent != nil:
+		b.misplaced = append(b.misplaced, fmt.Errorf("added to group %q, but it is nested in %q", g.base, b.parent.describeName()))
+	default:
+		b.group = g
+	}
+}
+
+// inGroup returns the group of the resource, which nested resources share
+// with their parent.
+func (b *Resource[T]) inGroup() *Group {
+	if b.group == nil && b.parent != nil {
+		return b.parent.inGroup()
+	}
+	return b.group
+}
+
+// allCodecs returns the codecs of the resource, followed by those of its
+// group which it does not override.
+func (b *Resource[T]) allCodecs() []Codec {
+	g := b.inGroup()
+	if g == nil || len(g.codecs) == 0 {
+		return b.codecs
+	}
+	codecs := append([]Codec(nil), b.codecs...)
+	for _, c := range g.codecs {
+		if findCodec(codecs, c.MediaType()) == nil {
+			codecs = append(codecs, c)
+		}
+	}
+	return codecs
+}
+
+func (b *Resource[T]) usesProblemDetails() bool {
+	g := b.inGroup()
+	return b.problemDetails || (g != nil && g.problemDetails)
+}
@@ -0,0 +1,290 @@
+package resource_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+	"github.com/iwanhae/resource/codec"
+)
+
+func TestGroup(t *testing.T) {
+	getWithName := func(name string) resource.Get[MockResource] {
+		return func(ctx resource.Context, id string) (MockResource, error) {
+			return MockResource{ID: id, Name: name}, nil
+		}
+	}
+
+	v1 := resource.NewGroup("/api/v1/").Add(
+		resource.New[MockResource]().Name("mock").Plural("mocks").Get(getWithName("v1")),
+	)
+	v2 := resource.NewGroup("api/v2").Add(
+		resource.New[MockResource]().Name("mock").Plural("mocks").Get(getWithName("v2")),
+		resource.New[MockResource]().Name("mock").Plural("mocks").Base("/legacy").Get(getWithName("legacy")),
+	)
+
+	mux := http.NewServeMux()
+	v1.RegisterMux(mux)
+	v2.RegisterMux(mux)
+
+	testCases := []struct {
+		path           string
+		expectedStatus int
+		expectedName   string
+	}{
+		{"/api/v1/mocks/1", http.StatusOK, "v1"},
+		{"/api/v2/mocks/1", http.StatusOK, "v2"},
+		{"/api/v2/legacy/mocks/1", http.StatusOK, "legacy"},
+		{"/mocks/1", http.StatusNotFound, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v",
+					rr.Code, tc.expectedStatus)
+			}
+			if tc.expectedName == "" {
+				return
+			}
+			var got MockResource
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("Could not decode response: %v", err)
+			}
+			if got.Name != tc.expectedName {
+				t.Errorf("unexpected handler: got %v want %v", got.Name, tc.expectedName)
+			}
+		})
+	}
+
+	if got := v2.Resources()[1].Describe().Routes[0].Path; got != "/api/v2/legacy/mocks/{mockId}" {
+		t.Errorf("unexpected described path: %v", got)
+	}
+}
+
+type MockTag struct {
+	ID string `json:"id"`
+}
+
+func (m MockTag) ValidateCreate(ctx resource.Context) error            { return nil }
+func (m MockTag) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func TestGroupSettings(t *testing.T) {
+	var calls []string
+	mocks := resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).Create(mockCreate).
+		Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, "resource")
+				next.ServeHTTP(w, r)
+			})
+		})
+	tags := resource.New[MockTag]().Name("tag").Plural("tags").
+		Get(func(ctx resource.Context, id string) (MockTag, error) { return MockTag{}, resource.ErrNotFound })
+	mocks.Nest(tags)
+
+	handler := resource.NewGroup("/api").
+		Add(mocks).
+		Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, "group")
+				next.ServeHTTP(w, r)
+			})
+		}).
+		Codecs(codec.XML).
+		ProblemDetails(true).
+		Handler()
+
+	testCases := []struct {
+		name                string
+		method              string
+		path                string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+		expectedCalls       []string
+	}{
+		{"Middlewares", "GET", "/api/mocks/1", "", http.StatusOK, resource.MIMEApplicationJSON, []string{"group", "resource"}},
+		{"GroupCodec", "GET", "/api/mocks/1", "application/xml", http.StatusOK, "application/xml", []string{"group", "resource"}},
+		{"ProblemDetails", "POST", "/api/mocks", "", http.StatusBadRequest, resource.MIMEApplicationProblemJSON, []string{"group", "resource"}},
+		{"NestedInheritsGroup", "GET", "/api/mocks/1/tags/1", "application/xml", http.StatusNotFound, resource.MIMEApplicationProblemXML, []string{"group"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = nil
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{}`))
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			if got := rr.Header().Get("Content-Type"); got != tc.expectedContentType {
+				t.Errorf("handler returned wrong content type: got %v want %v", got, tc.expectedContentType)
+			}
+			if !slices.Equal(calls, tc.expectedCalls) {
+				t.Errorf("unexpected middleware calls: got %v want %v", calls, tc.expectedCalls)
+			}
+		})
+	}
+}
+
+func TestGroupDiscovery(t *testing.T) {
+	tags := resource.New[MockTag]().Name("tag").Plural("tags").
+		List(func(ctx resource.Context, query resource.ListQuery) ([]MockTag, error) { return nil, nil })
+	mocks := resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).Delete(mockDelete).
+		RegisterSubresource("status", func(ctx resource.Context, w http.ResponseWriter, r *http.Request) {}).
+		Nest(tags)
+	handler := resource.NewGroup("/api/v1").Add(mocks).Discovery(true).Handler()
+
+	req := httptest.NewRequest("GET", "/api/v1/", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var got, expected any
+	json.Unmarshal(rr.Body.Bytes(), &got)
+	json.Unmarshal([]byte(`{"base":"/api/v1","resources":[
+		{"name":"mock","plural":"mocks","path":"/api/v1/mocks","verbs":["get","delete"],"subresources":["status"],"mediaTypes":["application/json"]},
+		{"name":"tag","plural":"tags","path":"/api/v1/mocks/{mockId}/tags","parent":"mock","verbs":["list"],"mediaTypes":["application/json"]}
+	]}`), &expected)
+	gotJSON, _ := json.Marshal(got)
+	expectedJSON, _ := json.Marshal(expected)
+	if string(gotJSON) != string(expectedJSON) {
+		t.Errorf("handler returned unexpected body: got %s want %s", gotJSON, expectedJSON)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/unknown", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestGroupRouteCollision(t *testing.T) {
+	g := resource.NewGroup("/api").Add(
+		resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet).Delete(mockDelete),
+		resource.New[MockTag]().Name("tag").Plural("mocks").
+			Get(func(ctx resource.Context, id string) (MockTag, error) { return MockTag{}, nil }),
+	)
+
+	mux := http.NewServeMux()
+	defer func() {
+		err, _ := recover().(error)
+		if err == nil {
+			t.Fatalf("expected RegisterMux to panic")
+		}
+		want := `route "GET /api/mocks/{tagId}" of operation get conflicts with "GET /api/mocks/{mockId}" of operation get`
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("unexpected error: got %q want it to contain %q", err, want)
+		}
+		// nothing was registered before the collision was found
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("DELETE", "/api/mocks/1", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+		}
+	}()
+	g.RegisterMux(mux)
+}
+
+func TestGroupBaseAfterAdd(t *testing.T) {
+	mocks := resource.New[MockResource]().Name("mock").Plural("mocks").Get(mockGet)
+	g := resource.NewGroup("/api").Add(mocks)
+	mocks.Base("/v1")
+
+	if got := mocks.Describe().Base; got != "/api/v1" {
+		t.Errorf("unexpected base: got %v want %v", got, "/api/v1")
+	}
+	handler := g.Handler()
+	req := httptest.NewRequest("GET", "/api/v1/mocks/1", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestGroupMisplaced(t *testing.T) {
+	users := resource.New[MockResource]().Name("user").Plural("users").Get(mockGet)
+	v1 := resource.NewGroup("/v1").Add(users)
+	v2 := resource.NewGroup("/v2").Add(users)
+	orders := resource.New[MockTag]().Name("order").Plural("orders").
+		Get(func(ctx resource.Context, id string) (MockTag, error) { return MockTag{}, nil })
+	users.Nest(orders)
+	v3 := resource.NewGroup("/v3").Add(orders)
+
+	// the first group is kept
+	if got := v1.Routes()[0].Path; got != "/v1/users/{userId}" {
+		t.Errorf("unexpected path: got %v want %v", got, "/v1/users/{userId}")
+	}
+	testCases := []struct {
+		name     string
+		group    *resource.Group
+		expected string
+	}{
+		{"SecondGroup", v2, `resource "user": added to group "/v2", but it is already in group "/v1"`},
+		{"NestedThenAdded", v3, `resource "order": added to group "/v3", but it is nested in "user"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.group.Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("unexpected error: got %v want it to contain %q", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGroupWildcardBase(t *testing.T) {
+	testCases := []struct {
+		name     string
+		group    *resource.Group
+		expected string
+	}{
+		{"ResourceBase", resource.NewGroup("/api").Add(
+			resource.New[MockResource]().Name("user").Plural("users").Get(mockGet),
+			resource.New[MockTag]().Name("item").Plural("items").Base("/{tenant}").
+				Get(func(ctx resource.Context, id string) (MockTag, error) { return MockTag{}, nil }),
+		), `resource "item": invalid base "/{tenant}": it must not contain wildcards`},
+		{"GroupBase", resource.NewGroup("/{version}").Add(
+			resource.New[MockResource]().Name("user").Plural("users").Get(mockGet),
+		), `group "/{version}": invalid base "/{version}": it must not contain wildcards`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.group.Validate()
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("unexpected error: got %v want it to contain %q", err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestGroupOverlappingRoutes(t *testing.T) {
+	// GET /x/y/{yId} and GET /x/{xId}/q both match /x/y/q, and neither is
+	// more specific than the other
+	g := resource.NewGroup("/api").Add(
+		resource.New[MockResource]().Name("y").Plural("y").Base("/x").Get(mockGet),
+		resource.New[MockResource]().Name("x").Plural("x").Get(mockGet).
+			Nest(resource.New[MockTag]().Name("q").Plural("q").
+				List(func(ctx resource.Context, query resource.ListQuery) ([]MockTag, error) { return nil, nil })),
+	)
+	want := `route "GET /api/x/{xId}/q" of operation list conflicts with "GET /api/x/y/{yId}" of operation get`
+	if err := g.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("unexpected error: got %v want it to contain %q", err, want)
+	}
+}
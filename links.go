@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// setOffsetLinks sets the RFC 8288 Link header of an offset based list.
+// Without a total, next is assumed to exist whenever the page is full.
+func setOffsetLinks(w http.ResponseWriter, r *http.Request, metadata Metadata, count int) {
+	offset := func(offset int) string {
+		return linkURL(r, map[string]string{"offset": strconv.Itoa(offset)})
+	}
+
+	var links []string
+	links = append(links, link(offset(0), "first"))
+	if metadata.Offset > 0 {
+		links = append(links, link(offset(max(metadata.Offset-metadata.Limit, 0)), "prev"))
+	}
+	hasMore := metadata.Limit > 0 && count >= metadata.Limit
+	if metadata.HasMore != nil {
+		hasMore = *metadata.HasMore
+	}
+	if hasMore {
+		links = append(links, link(offset(metadata.Offset+metadata.Limit), "next"))
+	}
+	if metadata.Total != nil && metadata.Limit > 0 {
+		last := 0
+		if *metadata.Total > 0 {
+			last = (*metadata.Total - 1) / metadata.Limit * metadata.Limit
+		}
+		links = append(links, link(offset(last), "last"))
+	}
+	w.Header().Set(HeaderLink, strings.Join(links, ", "))
+}
+
+func setCursorLinks(w http.ResponseWriter, r *http.Request, metadata Metadata) {
+	var links []string
+	links = append(links, link(linkURL(r, map[string]string{"cursor": ""}), "first"))
+	if metadata.PrevCursor != "" {
+		links = append(links, link(linkURL(r, map[string]string{"cursor": metadata.PrevCursor}), "prev"))
+	}
+	if metadata.NextCursor != "" {
+		links = append(links, link(linkURL(r, map[string]string{"cursor": metadata.NextCursor}), "next"))
+	}
+	w.Header().Set(HeaderLink, strings.Join(links, ", "))
+}
+
+// linkURL returns the request path and query with params replaced.
+// Empty values remove the parameter.
+func linkURL(r *http.Request, params map[string]string) string {
+	query := r.URL.Query()
+	for k, v := range params {
+		if v == "" {
+			query.Del(k)
+		} else {
+			query.Set(k, v)
+		}
+	}
+	if len(query) == 0 {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + query.Encode()
+}
+
+func link(url string, rel string) string {
+	return fmt.Sprintf("<%s>; rel=%q", url, rel)
+}
@@ -0,0 +1,72 @@
+package resource_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+func TestListTotalAndLinks(t *testing.T) {
+	const total = 25
+	list := func(ctx resource.Context, query resource.ListQuery) ([]MockResource, error) {
+		n := max(min(query.Limit, total-query.Offset), 0)
+		return make([]MockResource, n), nil
+	}
+	counted := resource.New[MockResource]().Name("mock").Plural("mocks").List(list).
+		Count(func(ctx resource.Context, query resource.ListQuery) (int, error) { return total, nil }).
+		Handler()
+	uncounted := resource.New[MockResource]().Name("mock").Plural("mocks").List(list).
+		Handler()
+
+	testCases := []struct {
+		name            string
+		path            string
+		counted         bool
+		expectedLink    string
+		expectedHasMore bool
+	}{
+		{"FirstPage", "/mocks?limit=10&name=x", true,
+			`</mocks?limit=10&name=x&offset=0>; rel="first", </mocks?limit=10&name=x&offset=10>; rel="next", </mocks?limit=10&name=x&offset=20>; rel="last"`, true},
+		{"MiddlePage", "/mocks?limit=10&offset=12", true,
+			`</mocks?limit=10&offset=0>; rel="first", </mocks?limit=10&offset=2>; rel="prev", </mocks?limit=10&offset=22>; rel="next", </mocks?limit=10&offset=20>; rel="last"`, true},
+		{"LastPage", "/mocks?limit=10&offset=20", true,
+			`</mocks?limit=10&offset=0>; rel="first", </mocks?limit=10&offset=10>; rel="prev", </mocks?limit=10&offset=20>; rel="last"`, false},
+		{"Uncounted", "/mocks?limit=10&offset=10", false,
+			`</mocks?limit=10&offset=0>; rel="first", </mocks?limit=10&offset=0>; rel="prev", </mocks?limit=10&offset=20>; rel="next"`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := uncounted
+			if tc.counted {
+				handler = counted
+			}
+			req := httptest.NewRequest("GET", tc.path, nil)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("Link"); got != tc.expectedLink {
+				t.Errorf("unexpected Link header:\ngot  %s\nwant %s", got, tc.expectedLink)
+			}
+
+			var result resource.ResourceList[MockResource]
+			if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+				t.Fatalf("Could not decode response: %v", err)
+			}
+			if !tc.counted {
+				if result.Total != nil || result.HasMore != nil {
+					t.Errorf("expected no total without a count callback")
+				}
+				return
+			}
+			if result.Total == nil || *result.Total != total {
+				t.Errorf("unexpected total: got %v want %v", result.Total, total)
+			}
+			if result.HasMore == nil || *result.HasMore != tc.expectedHasMore {
+				t.Errorf("unexpected hasMore: got %v want %v", result.HasMore, tc.expectedHasMore)
+			}
+		})
+	}
+}
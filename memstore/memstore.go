@@ -0,0 +1,205 @@
+// Package memstore is a thread-safe in-memory implementation of the
+// callbacks of a resource, for prototypes and tests:
+//
+//	users := resource.New[User]().Name("user").Plural("users")
+//	memstore.New(func(u *User) *string { return &u.ID }).Bind(users)
+package memstore
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/iwanhae/resource"
+)
+
+// Store keeps items in insertion order. Items are stored and returned by
+// value, so fields of reference types are shared with the callers.
+type Store[T resource.Validator] struct {
+	mu     sync.RWMutex
+	id     func(item *T) *string
+	items  map[string]T
+	order  []string
+	nextID int
+
+	onChange func(items []T, lastID int) error
+}
+
+// New returns an empty store. id returns a pointer to the ID field of an item.
+func New[T resource.Validator](id func(item *T) *string) *Store[T] {
+	return &Store[T]{
+		id:    id,
+		items: make(map[string]T),
+	}
+}
+
+// Load replaces the items of the store, e.g. with those saved by OnChange.
+// lastID is the last generated ID, which is not generated again.
+func (s *Store[T]) Load(items []T, lastID int) *Store[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[string]T, len(items))
+	s.order = nil
+	for _, item := range items {
+		id := *s.id(&item)
+		if _, ok := s.items[id]; !ok {
+			s.order = append(s.order, id)
+		}
+		s.items[id] = item
+	}
+	s.nextID = lastID
+	return s
+}
+
+// OnChange sets a function called on every change with the items, in
+// insertion order, and the last generated ID as they are after it. It is
+// called before the change is applied, and the change is discarded when it
+// fails, so that it can persist the store.
+func (s *Store[T]) OnChange(onChange func(items []T, lastID int) error) *Store[T] {
+	s.onChange = onChange
+	return s
+}
+
+// Bind sets every callback of r, which supports the filter, sort and
+// pagination parameters of list requests.
+func (s *Store[T]) Bind(r *resource.Resource[T]) *resource.Resource[T] {
+	return r.
+		List(s.List).
+		Count(s.Count).
+		Create(s.Create).
+		Get(s.Get).
+		Update(s.Update).
+		Patch(s.Update).
+		Delete(s.Delete)
+}
+
+func (s *Store[T]) List(ctx resource.Context, query resource.ListQuery) ([]T, error) {
+	page, _, err := resource.ApplyListQuery(s.snapshot(), query)
+	return page, err
+}
+
+func (s *Store[T]) Count(ctx resource.Context, query resource.ListQuery) (int, error) {
+	query.Offset, query.Limit = 0, 0
+	_, total, err := resource.ApplyListQuery(s.snapshot(), query)
+	return total, err
+}
+
+// Create stores item, with a generated ID unless it has one already.
+func (s *Store[T]) Create(ctx resource.Context, item T) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.id(&item)
+	nextID := s.nextID
+	if *id == "" {
+		for {
+			nextID++
+			*id = strconv.Itoa(nextID)
+			if _, ok := s.items[*id]; !ok {
+				break
+			}
+		}
+	} else if _, ok := s.items[*id]; ok {
+		return item, resource.ErrConflict.WithMessage(fmt.Sprintf("%q already exists", *id))
+	}
+	if err := s.change(*id, &item, nextID); err != nil {
+		return item, err
+	}
+	s.nextID = nextID
+	s.items[*id] = item
+	s.order = append(s.order, *id)
+	return item, nil
+}
+
+func (s *Store[T]) Get(ctx resource.Context, id string) (T, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	item, ok := s.items[id]
+	if !ok {
+		return item, notFound(id)
+	}
+	return item, nil
+}
+
+// Update replaces the item with the given id. The ID of item is set to id.
+// Update and Delete check the If-Match header of the request against the
+// stored item, see resource.Versioner.
+func (s *Store[T]) Update(ctx resource.Context, id string, item T) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.items[id]
+	if !ok {
+		return item, notFound(id)
+	}
+	if err := ctx.CheckIfMatch(current); err != nil {
+		return item, err
+	}
+	*s.id(&item) = id
+	if err := s.change(id, &item, s.nextID); err != nil {
+		return item, err
+	}
+	s.items[id] = item
+	return item, nil
+}
+
+func (s *Store[T]) Delete(ctx resource.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.items[id]
+	if !ok {
+		return notFound(id)
+	}
+	if err := ctx.CheckIfMatch(current); err != nil {
+		return err
+	}
+	if err := s.change(id, nil, s.nextID); err != nil {
+		return err
+	}
+	delete(s.items, id)
+	for i, existing := range s.order {
+		if existing == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// change calls the OnChange function with the items as they are once the
+// item with the given id is replaced by item, appended when it is new, or
+// removed when item is nil.
+func (s *Store[T]) change(id string, item *T, lastID int) error {
+	if s.onChange == nil {
+		return nil
+	}
+	items := make([]T, 0, len(s.order)+1)
+	found := false
+	for _, existing := range s.order {
+		if existing != id {
+			items = append(items, s.items[existing])
+			continue
+		}
+		found = true
+		if item != nil {
+			items = append(items, *item)
+		}
+	}
+	if !found && item != nil {
+		items = append(items, *item)
+	}
+	return s.onChange(items, lastID)
+}
+
+// snapshot copies the items in insertion order.
+func (s *Store[T]) snapshot() []T {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]T, 0, len(s.order))
+	for _, id := range s.order {
+		items = append(items, s.items[id])
+	}
+	return items
+}
+
+func notFound(id string) error {
+	return resource.ErrNotFound.WithMessage(fmt.Sprintf("%q not found", id))
+}
@@ -0,0 +1,167 @@
+package memstore_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+	"github.com/iwanhae/resource/memstore"
+)
+
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func (u User) ValidateCreate(ctx resource.Context) error            { return nil }
+func (u User) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func TestStore(t *testing.T) {
+	users := resource.New[User]().
+		Name("user").
+		Plural("users").
+		Filterable("name", "age").
+		Sortable("age")
+	memstore.New(func(u *User) *string { return &u.ID }).Bind(users)
+	handler := users.Handler()
+
+	testCases := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"CreateAlice", "POST", "/users", `{"name":"alice","age":30}`, http.StatusCreated, `{"id":"1","name":"alice","age":30}`},
+		{"CreateBob", "POST", "/users", `{"name":"bob","age":20}`, http.StatusCreated, `{"id":"2","name":"bob","age":20}`},
+		{"CreateWithID", "POST", "/users", `{"id":"carol","name":"carol","age":40}`, http.StatusCreated, `{"id":"carol","name":"carol","age":40}`},
+		{"CreateConflict", "POST", "/users", `{"id":"carol","name":"carol"}`, http.StatusConflict, ""},
+		{"Get", "GET", "/users/1", "", http.StatusOK, `{"id":"1","name":"alice","age":30}`},
+		{"Update", "PUT", "/users/2", `{"name":"bobby","age":21}`, http.StatusOK, `{"id":"2","name":"bobby","age":21}`},
+		{"UpdateNotFound", "PUT", "/users/9", `{"name":"x"}`, http.StatusNotFound, ""},
+		{"List", "GET", "/users?filter=age+ge+21&sort=-age", "", http.StatusOK,
+			`{"items":[{"id":"carol","name":"carol","age":40},{"id":"1","name":"alice","age":30},{"id":"2","name":"bobby","age":21}],"metadata":{"offset":0,"limit":10,"total":3,"hasMore":false}}`},
+		{"ListPage", "GET", "/users?limit=1&offset=1", "", http.StatusOK,
+			`{"items":[{"id":"2","name":"bobby","age":21}],"metadata":{"offset":1,"limit":1,"total":3,"hasMore":true}}`},
+		{"Delete", "DELETE", "/users/1", "", http.StatusNoContent, ""},
+		{"GetDeleted", "GET", "/users/1", "", http.StatusNotFound, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			if tc.expectedBody == "" {
+				return
+			}
+			var got, expected any
+			json.Unmarshal(rr.Body.Bytes(), &got)
+			json.Unmarshal([]byte(tc.expectedBody), &expected)
+			gotJSON, _ := json.Marshal(got)
+			expectedJSON, _ := json.Marshal(expected)
+			if string(gotJSON) != string(expectedJSON) {
+				t.Errorf("handler returned unexpected body: got %s want %s", gotJSON, expectedJSON)
+			}
+		})
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	var saved []User
+	fail := false
+	store := memstore.New(func(u *User) *string { return &u.ID }).
+		Load([]User{{ID: "1", Name: "alice"}}, 1).
+		OnChange(func(items []User, lastID int) error {
+			if fail {
+				return errors.New("disk full")
+			}
+			saved = items
+			return nil
+		})
+	ctx := resource.Context{}
+
+	if _, err := store.Create(ctx, User{Name: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || saved[1].ID != "2" {
+		t.Errorf("unexpected items after create: %+v", saved)
+	}
+	if err := store.Delete(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].ID != "2" {
+		t.Errorf("unexpected items after delete: %+v", saved)
+	}
+
+	fail = true
+	if _, err := store.Update(ctx, "2", User{Name: "bobby"}); err == nil {
+		t.Fatalf("expected the error of OnChange")
+	}
+	if item, _ := store.Get(ctx, "2"); item.Name != "bob" {
+		t.Errorf("expected the failed update to be discarded, got %+v", item)
+	}
+}
+
+type Document struct {
+	ID       string `json:"id"`
+	Revision string `json:"revision"`
+}
+
+func (d Document) ValidateCreate(ctx resource.Context) error            { return nil }
+func (d Document) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+func (d Document) Version() string                                      { return d.Revision }
+
+func TestIfMatch(t *testing.T) {
+	store := memstore.New(func(d *Document) *string { return &d.ID }).
+		Load([]Document{{ID: "1", Revision: "2"}}, 1)
+	// a stale Get passes the check of the handler, as a concurrent write
+	// would, so that the precondition is left to the store
+	docs := store.Bind(resource.New[Document]().Name("doc").Plural("docs")).
+		Get(func(ctx resource.Context, id string) (Document, error) {
+			return Document{ID: id, Revision: "1"}, nil
+		})
+	handler := docs.Handler()
+
+	testCases := []struct {
+		name           string
+		method         string
+		ifMatch        string
+		expectedStatus int
+	}{
+		{"UpdateStale", "PUT", `"1"`, http.StatusPreconditionFailed},
+		{"DeleteStale", "DELETE", `"1"`, http.StatusPreconditionFailed},
+		{"Update", "PUT", "", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/docs/1", strings.NewReader(`{"revision":"3"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+		})
+	}
+}
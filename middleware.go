@@ -0,0 +1,44 @@
+package resource
+
+import (
+	"net/http"
+)
+
+// Middleware wraps the handler of an operation, e.g. for logging or authentication.
+type Middleware func(next http.Handler) http.Handler
+
+// Use adds middlewares to every operation of the resource, including subresources.
+// Middlewares run in the order they are added, then authentication and
+// authorization, then the middlewares added with UseAfterAuth, and then
+// those added with UseOn.
+func (r *Resource[T]) Use(middlewares ...Middleware) *Resource[T] {
+	r.middlewares = append(r.middlewares, middlewares...)
+	return r
+}
+
+// UseAfterAuth adds middlewares to every operation of the resource, which
+// run once the request is authenticated and authorized and the parent item
+// of nested resources is found, e.g. request validation, which must not
+// answer callers who may not make the request.
+func (r *Resource[T]) UseAfterAuth(middlewares ...Middleware) *Resource[T] {
+	r.afterAuthMiddlewares = append(r.afterAuthMiddlewares, middlewares...)
+	return r
+}
+
+// UseOn adds middlewares to the given operations only, e.g.
+//
+//	r.UseOn([]resource.Operation{resource.OperationDelete}, requireAdmin)
+func (r *Resource[T]) UseOn(operations []Operation, middlewares ...Middleware) *Resource[T] {
+	for _, op := range operations {
+		r.operationMiddlewares[op] = append(r.operationMiddlewares[op], middlewares...)
+	}
+	return r
+}
+
+// chain wraps h so that the first middleware is the outermost one.
+func chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
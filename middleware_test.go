@@ -0,0 +1,68 @@
+package resource_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) resource.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+	}
+
+	handler := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		Get(mockGet).
+		Delete(mockDelete).
+		RegisterSubresource("user", func(ctx resource.Context, w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, "subresource")
+		}).
+		UseAfterAuth(record("afterAuth")).
+		Use(record("first"), record("second")).
+		UseOn([]resource.Operation{resource.OperationGet, resource.OperationSubresource}, record("operation")).
+		UseOn([]resource.Operation{resource.OperationDelete}, deny).
+		Handler()
+
+	testCases := []struct {
+		method         string
+		path           string
+		expectedStatus int
+		expectedCalls  []string
+	}{
+		{"GET", "/mocks/1", http.StatusOK, []string{"first", "second", "afterAuth", "operation"}},
+		{"DELETE", "/mocks/1", http.StatusForbidden, []string{"first", "second", "afterAuth"}},
+		{"GET", "/mocks/1/user/", http.StatusOK, []string{"first", "second", "afterAuth", "operation", "subresource"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			calls = nil
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(""))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if !reflect.DeepEqual(calls, tc.expectedCalls) {
+				t.Errorf("unexpected calls: got %v want %v", calls, tc.expectedCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// parent is the view of a Resource its nested resources depend on.
+type parent interface {
+	itemPath() string
+	checkExists(r *http.Request) error
+	pathID() string
+	describeName() string
+	inGroup() *Group
+	allAuthenticators() []Authenticator
+	authorizes() bool
+	authorizeItem(r *http.Request) error
+	usesPathID(id string) bool
+}
+
+// Nest mounts child resources under the items of r, e.g. orders under
+// users serve /users/{userId}/orders and /users/{userId}/orders/{orderId}.
+// Every request on a child first checks that the parent exists with the
+// Get callback of r, and the id of the parent is available with
+// Context.ParentID. Children without authenticators of their own use those
+// of r, and the authorizer of r must allow getting the parent item.
+// Children are registered and described along with r, so they must not be
+// added to a Group on their own.
+func (r *Resource[T]) Nest(children ...Registrar) *Resource[T] {
+	for _, child := range children {
+		child.setParent(r)
+		r.children = append(r.children, child)
+	}
+	return r
+}
+
+func (b *Resource[T]) setParent(p parent) {
+	switch {
+	case b.parent != nil:
+		b.misplaced = append(b.misplaced, fmt.Errorf("nested in %q, but it is already nested in %q", p.describeName(), b.parent.describeName()))
+	case b.group != nil:
+		b.misplaced = append(b.misplaced, fmt.Errorf("nested in %q, but it is in group %q", p.describeName(), b.group.base))
+	default:
+		b.parent = p
+	}
+}
+
+// usesPathID reports whether the resource or one of its parents has the
+// path wildcard id.
+func (b *Resource[T]) usesPathID(id string) bool {
+	return b.pathID() == id || (b.parent != nil && b.parent.usesPathID(id))
+}
+
+func (b *Resource[T]) describeName() string {
+	return b.name
+}
+
+// basePath is the base path of the resource, below the parent item for
+// nested resources, and below the base path of its group otherwise.
+func (b *Resource[T]) basePath() string {
+	switch {
+	case b.parent != nil:
+		return b.parent.itemPath() + b.base
+	case b.group != nil:
+		return b.group.base + b.base
+	}
+	return b.base
+}
+
+func (b *Resource[T]) collectionPath() string {
+	return b.basePath() + "/" + b.plural
+}
+
+func (b *Resource[T]) itemPath() string {
+	return b.collectionPath() + "/{" + b.pathID() + "}"
+}
+
+// checkExists gets the item addressed by the request, and those of its parents.
+// Resources without a Get callback are assumed to exist.
+func (b *Resource[T]) checkExists(r *http.Request) error {
+	if b.parent != nil {
+		if err := b.parent.checkExists(r); err != nil {
+			return err
+		}
+	}
+	if b.get == nil {
+		return nil
+	}
+	_, err := b.get(newContext(r), r.PathValue(b.pathID()))
+	return err
+}
+
+// parentMiddleware rejects requests on items of parents which do not exist.
+// Parents report missing items with ErrNotFound, other errors being 500.
+func (b *Resource[T]) parentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := b.parent.checkExists(r); err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ParentID returns the id of the parent item of a nested resource.
+func (c Context) ParentID() string {
+	return c.info().parentID
+}
@@ -0,0 +1,167 @@
+package resource_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type Order struct {
+	ID     string `json:"id"`
+	UserID string `json:"userId"`
+	Item   string `json:"item"`
+}
+
+func (o Order) ValidateCreate(ctx resource.Context) error            { return nil }
+func (o Order) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func TestNest(t *testing.T) {
+	orders := resource.New[Order]().
+		Name("order").
+		Plural("orders").
+		List(func(ctx resource.Context, query resource.ListQuery) ([]Order, error) {
+			return []Order{{ID: "1", UserID: ctx.ParentID(), Item: "book"}}, nil
+		}).
+		Create(func(ctx resource.Context, o Order) (Order, error) {
+			o.ID = "2"
+			o.UserID = ctx.ParentID()
+			return o, nil
+		}).
+		Get(func(ctx resource.Context, id string) (Order, error) {
+			return Order{ID: id, UserID: ctx.PathValue("userId")}, nil
+		})
+	users := resource.New[MockResource]().
+		Name("user").
+		Plural("users").
+		Get(func(ctx resource.Context, id string) (MockResource, error) {
+			switch id {
+			case "alice":
+			case "broken":
+				return MockResource{}, errors.New("db down")
+			default:
+				return MockResource{}, resource.ErrNotFound
+			}
+			return MockResource{ID: id}, nil
+		}).
+		Nest(orders)
+	handler := resource.NewGroup("/api").Add(users).Handler()
+
+	testCases := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		expectedStatus int
+		expectedUserID string
+	}{
+		{"Create", "POST", "/api/users/alice/orders", `{"item":"pen"}`, http.StatusCreated, "alice"},
+		{"Get", "GET", "/api/users/alice/orders/1", "", http.StatusOK, "alice"},
+		{"ParentNotFound", "GET", "/api/users/bob/orders", "", http.StatusNotFound, ""},
+		{"ParentNotFoundItem", "GET", "/api/users/bob/orders/1", "", http.StatusNotFound, ""},
+		{"ParentFailed", "GET", "/api/users/broken/orders", "", http.StatusInternalServerError, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					rr.Code, tc.expectedStatus)
+			}
+			if tc.expectedUserID == "" {
+				return
+			}
+			var order Order
+			if err := json.NewDecoder(rr.Body).Decode(&order); err != nil {
+				t.Fatalf("Could not decode order: %v", err)
+			}
+			if order.UserID != tc.expectedUserID {
+				t.Errorf("Unexpected parent id: got %v want %v", order.UserID, tc.expectedUserID)
+			}
+		})
+	}
+
+	d := users.Describe()
+	if len(d.Nested) != 1 || d.Nested[0].Parent != "user" || d.Nested[0].Routes[0].Path != "/api/users/{userId}/orders" {
+		t.Errorf("Unexpected description of nested resources: %+v", d.Nested)
+	}
+}
+
+func TestNestInheritsAuth(t *testing.T) {
+	orders := resource.New[Order]().
+		Name("order").
+		Plural("orders").
+		List(func(ctx resource.Context, query resource.ListQuery) ([]Order, error) {
+			if ctx.Principal() == nil {
+				t.Errorf("expected the principal of the parent's authenticator")
+			}
+			return nil, nil
+		})
+	accounts := resource.New[MockResource]().
+		Name("account").
+		Plural("accounts").
+		Authenticate(resource.BearerAuth(func(ctx context.Context, token string) (*resource.Principal, error) {
+			return &resource.Principal{Subject: token}, nil
+		})).
+		Authorize(func(ctx resource.Context, req resource.AuthorizationRequest) error {
+			if req.ID != req.Principal.Subject {
+				return errors.New("not your account")
+			}
+			return nil
+		}).
+		Get(func(ctx resource.Context, id string) (MockResource, error) {
+			if ctx.Principal() == nil {
+				t.Errorf("expected the parent to be checked with the principal")
+			}
+			return MockResource{ID: id}, nil
+		}).
+		Nest(orders)
+	handler := accounts.Handler()
+
+	testCases := []struct {
+		name           string
+		target         string
+		token          string
+		expectedStatus int
+	}{
+		{"NoCredentials", "/accounts/1/orders", "", http.StatusUnauthorized},
+		{"OtherAccount", "/accounts/1/orders", "2", http.StatusForbidden},
+		{"OwnAccount", "/accounts/1/orders", "1", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.target, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					rr.Code, tc.expectedStatus)
+			}
+			if tc.expectedStatus == http.StatusUnauthorized && rr.Header().Get(resource.HeaderWWWAuthenticate) == "" {
+				t.Errorf("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+
+	d := accounts.Describe().Nested[0]
+	if len(d.Security) != 1 || !d.Authorized {
+		t.Errorf("expected the nested resource to be described with the parent's auth: %+v", d)
+	}
+}
@@ -0,0 +1,34 @@
+package openapi3
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/iwanhae/resource"
+)
+
+// Group adds the resources of each group to the document, along with their
+// discovery endpoint, so that a whole API is described by one document.
+func (b *builder) Group(groups ...*resource.Group) *builder {
+	for _, g := range groups {
+		b.Resource(g.Resources()...)
+		for _, route := range g.Routes() {
+			if route.Operation == resource.OperationDiscovery {
+				b.addDiscovery(strings.TrimSuffix(route.Path, "{$}"))
+			}
+		}
+	}
+	return b
+}
+
+func (b *builder) addDiscovery(path string) {
+	op := openapi3.NewOperation()
+	op.OperationID = "discover" + upperFirst(strings.ReplaceAll(path, "/", "_"))
+	op.Summary = "List the resources of " + path
+	op.Tags = []string{"discovery"}
+	op.Responses = openapi3.NewResponses()
+	op.AddResponse(http.StatusOK, jsonResponse("OK", b.schemaRefFor(reflect.TypeFor[resource.APIResourceList]())))
+	b.addOperation(path, http.MethodGet, op)
+}
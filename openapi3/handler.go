@@ -0,0 +1,49 @@
+package openapi3
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed docs.html
+var docsHTML []byte
+
+// RegisterMux serves the built document under prefix as openapi.json and
+// openapi.yaml, along with a self-contained HTML page at docs.
+// The document is built once, so register every resource beforehand.
+func (b *builder) RegisterMux(mux *http.ServeMux, prefix string) *builder {
+	doc := b.Build()
+
+	rawJSON, err := json.Marshal(&doc)
+	if err != nil {
+		panic(fmt.Errorf("failed to marshal openapi document: %w", err))
+	}
+	rawYAML, err := yaml.Marshal(&doc)
+	if err != nil {
+		panic(fmt.Errorf("failed to marshal openapi document: %w", err))
+	}
+
+	mux.HandleFunc(fmt.Sprintf("GET %s/openapi.json", prefix), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(rawJSON)
+	})
+	mux.HandleFunc(fmt.Sprintf("GET %s/openapi.yaml", prefix), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(rawYAML)
+	})
+	mux.HandleFunc(fmt.Sprintf("GET %s/docs", prefix), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(docsHTML)
+	})
+	return b
+}
+
+func (b *builder) Handler() http.Handler {
+	mux := http.NewServeMux()
+	b.RegisterMux(mux, "")
+	return mux
+}
@@ -0,0 +1,43 @@
+package openapi3_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource/openapi3"
+)
+
+func TestHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	newMockResource().RegisterMux(mux)
+	openapi3.NewBuilder().Resource(newMockResource()).RegisterMux(mux, "/api")
+
+	testCases := []struct {
+		path        string
+		contentType string
+		contains    string
+	}{
+		{"/api/openapi.json", "application/json", `"/mocks/{mockId}"`},
+		{"/api/openapi.yaml", "application/yaml", "/mocks/{mockId}:"},
+		{"/api/docs", "text/html; charset=utf-8", "openapi.json"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("unexpected content type: got %v want %v", got, tc.contentType)
+			}
+			if !strings.Contains(rr.Body.String(), tc.contains) {
+				t.Errorf("expected body to contain %q", tc.contains)
+			}
+		})
+	}
+}
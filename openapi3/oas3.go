@@ -7,9 +7,12 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/iwanhae/resource"
 )
 
 var (
@@ -19,18 +22,37 @@ var (
 
 func NewBuilder() *builder {
 	return &builder{
-		schemas: make(openapi3.Schemas),
+		schemas:         make(openapi3.Schemas),
+		securitySchemes: make(openapi3.SecuritySchemes),
+		paths:           openapi3.NewPaths(),
+		info: &openapi3.Info{
+			Title:   "API",
+			Version: "0.0.0",
+		},
 	}
 }
 
 type builder struct {
-	schemas openapi3.Schemas
+	schemas         openapi3.Schemas
+	securitySchemes openapi3.SecuritySchemes
+	paths           *openapi3.Paths
+	info            *openapi3.Info
+}
+
+func (b *builder) Info(title string, version string) *builder {
+	b.info.Title = title
+	b.info.Version = version
+	return b
 }
 
 func (b *builder) Build() openapi3.T {
 	return openapi3.T{
+		OpenAPI: "3.0.3",
+		Info:    b.info,
+		Paths:   b.paths,
 		Components: &openapi3.Components{
-			Schemas: b.schemas,
+			Schemas:         b.schemas,
+			SecuritySchemes: b.securitySchemes,
 		},
 	}
 }
@@ -50,10 +72,28 @@ func (b *builder) Register(t reflect.Type) string {
 			jsonTag = strings.Split(jsonTag, ",")[0]
 			if jsonTag != "" && jsonTag != "-" {
 				fieldType, isPtr := derefType(field.Type)
-				if !isPtr {
+				omitEmpty := strings.Contains(field.Tag.Get("json"), ",omitempty")
+				if !isPtr && !omitEmpty && fieldType.Kind() != reflect.Interface {
 					schema.Required = append(schema.Required, jsonTag)
 				}
 				ref := b.schemaRefFor(fieldType)
+				rules, err := resource.FieldRules(field)
+				if err != nil {
+					panic(err)
+				}
+				if rules.Required && !slices.Contains(schema.Required, jsonTag) {
+					schema.Required = append(schema.Required, jsonTag)
+				}
+				if ref.Value != nil {
+					applyRules(ref.Value, rules)
+					// encoding/json writes null for nil values of these kinds
+					switch fieldType.Kind() {
+					case reflect.Interface, reflect.Slice, reflect.Map:
+						ref.Value.Nullable = !omitEmpty && !rules.Required
+					default:
+						ref.Value.Nullable = isPtr && !omitEmpty && !rules.Required
+					}
+				}
 				schema.Properties[jsonTag] = ref
 			}
 		}
@@ -86,18 +126,60 @@ func (b *builder) schemaRefFor(t reflect.Type) *openapi3.SchemaRef {
 		case reflect.Struct:
 			ref := b.Register(t)
 			schemaRef = openapi3.NewSchemaRef(ref, nil)
-		case reflect.Array, reflect.Slice:
+		case reflect.Slice, reflect.Array:
+			if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+				schemaRef = openapi3.NewSchemaRef("", openapi3.NewBytesSchema())
+				break
+			}
 			schema := openapi3.NewArraySchema()
-			itemType, _ := dearrType(t)
+			itemType, _ := derefType(t.Elem())
 			ref := b.schemaRefFor(itemType)
 			schema.Items = ref
 			schemaRef = openapi3.NewSchemaRef("", schema)
+		case reflect.Map:
+			schema := openapi3.NewObjectSchema()
+			valueType, _ := derefType(t.Elem())
+			schema.AdditionalProperties = openapi3.AdditionalProperties{Schema: b.schemaRefFor(valueType)}
+			schemaRef = openapi3.NewSchemaRef("", schema)
+		default:
+			schemaRef = openapi3.NewSchemaRef("", openapi3.NewSchema())
 		}
 	}
 
 	return schemaRef
 }
 
+// applyRules emits the validate tag rules of a field as schema constraints.
+func applyRules(schema *openapi3.Schema, rules resource.Rules) {
+	schema.Min = rules.Minimum
+	schema.Max = rules.Maximum
+	if rules.MinLength != nil {
+		schema.MinLength = uint64(*rules.MinLength)
+	}
+	if rules.MaxLength != nil {
+		schema.MaxLength = openapi3.Uint64Ptr(uint64(*rules.MaxLength))
+	}
+	if rules.MinItems != nil {
+		schema.MinItems = uint64(*rules.MinItems)
+	}
+	if rules.MaxItems != nil {
+		schema.MaxItems = openapi3.Uint64Ptr(uint64(*rules.MaxItems))
+	}
+	schema.Pattern = rules.Pattern
+	if rules.Format != "" {
+		schema.Format = rules.Format
+	}
+	for _, value := range rules.Enum {
+		if schema.Type.Is(openapi3.TypeInteger) || schema.Type.Is(openapi3.TypeNumber) {
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				schema.Enum = append(schema.Enum, n)
+				continue
+			}
+		}
+		schema.Enum = append(schema.Enum, value)
+	}
+}
+
 func derefType(t reflect.Type) (deref reflect.Type, isPtr bool) {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
@@ -105,13 +187,6 @@ func derefType(t reflect.Type) (deref reflect.Type, isPtr bool) {
 	}
 	return t, isPtr
 }
-func dearrType(t reflect.Type) (item reflect.Type, isArr bool) {
-	for t.Kind() == reflect.Array || t.Kind() == reflect.Slice {
-		t = t.Elem()
-		isArr = true
-	}
-	return t, isArr
-}
 
 func camelCase(s string) string {
 
@@ -0,0 +1,371 @@
+package openapi3
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/iwanhae/resource"
+)
+
+// Resource adds the paths and operations served by each resource to the document.
+func (b *builder) Resource(resources ...resource.Describer) *builder {
+	for _, r := range resources {
+		b.addResource(r.Describe())
+	}
+	return b
+}
+
+func (b *builder) addResource(d resource.Description) {
+	t, _ := derefType(d.Type)
+	item := b.schemaRefFor(t)
+	input := b.inputSchemaRef(item)
+	list := b.listSchemaRef(d, item)
+	errorResponse := b.errorResponseFunc(d)
+
+	// nested resources are prefixed with their parent, e.g. listUserOrders
+	name := upperFirst(d.Parent) + upperFirst(d.Name)
+	plural := upperFirst(d.Parent) + upperFirst(d.Plural)
+
+	for _, route := range d.Routes {
+		op := openapi3.NewOperation()
+		op.Tags = []string{d.Plural}
+		op.Responses = openapi3.NewResponses()
+
+		switch route.Operation {
+		case resource.OperationList:
+			op.OperationID = "list" + plural
+			op.Summary = "List " + d.Plural
+			limit := openapi3.NewIntegerSchema().WithMin(1)
+			if d.MaxLimit > 0 {
+				limit.WithMax(float64(d.MaxLimit))
+			}
+			op.AddParameter(openapi3.NewQueryParameter("limit").WithSchema(limit))
+			if d.Cursor {
+				op.AddParameter(openapi3.NewQueryParameter("cursor").
+					WithDescription("nextCursor or prevCursor of a previous page").
+					WithSchema(openapi3.NewStringSchema()))
+			} else {
+				op.AddParameter(openapi3.NewQueryParameter("offset").WithSchema(openapi3.NewIntegerSchema().WithMin(0)))
+			}
+			addListQueryParameters(op, d)
+			listResponse := jsonResponse("OK", list)
+			listResponse.Headers = openapi3.Headers{
+				resource.HeaderLink: &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+					Description: "RFC 8288 links to the first, prev, next and last pages",
+					Schema:      openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+				}}},
+			}
+			op.AddResponse(http.StatusOK, listResponse)
+			op.AddResponse(http.StatusBadRequest, errorResponse(http.StatusBadRequest))
+		case resource.OperationCreate:
+			op.OperationID = "create" + name
+			op.Summary = "Create a " + d.Name
+			op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(input)}
+			op.AddResponse(http.StatusCreated, jsonResponse(http.StatusText(http.StatusCreated), item))
+			op.AddResponse(http.StatusBadRequest, errorResponse(http.StatusBadRequest))
+			op.AddResponse(http.StatusUnprocessableEntity, errorResponse(http.StatusUnprocessableEntity))
+		case resource.OperationGet:
+			op.OperationID = "get" + name
+			op.Summary = "Get a " + d.Name
+			if len(d.Selectable) > 0 {
+				op.AddParameter(fieldsParameter(d.Selectable))
+			}
+			op.AddResponse(http.StatusOK, jsonResponse("OK", item))
+			op.AddResponse(http.StatusNotFound, errorResponse(http.StatusNotFound))
+		case resource.OperationUpdate:
+			op.OperationID = "update" + name
+			op.Summary = "Update a " + d.Name
+			op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(input)}
+			op.AddResponse(http.StatusOK, jsonResponse("OK", item))
+			op.AddResponse(http.StatusBadRequest, errorResponse(http.StatusBadRequest))
+			op.AddResponse(http.StatusUnprocessableEntity, errorResponse(http.StatusUnprocessableEntity))
+			op.AddResponse(http.StatusNotFound, errorResponse(http.StatusNotFound))
+		case resource.OperationPatch:
+			op.OperationID = "patch" + name
+			op.Summary = "Patch a " + d.Name
+			op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithContent(openapi3.Content{
+				resource.MIMEApplicationMergePatchJSON: openapi3.NewMediaType().WithSchema(openapi3.NewObjectSchema()),
+				resource.MIMEApplicationJSONPatchJSON:  openapi3.NewMediaType().WithSchemaRef(b.jsonPatchSchemaRef()),
+			})}
+			op.AddResponse(http.StatusOK, jsonResponse("OK", item))
+			op.AddResponse(http.StatusBadRequest, errorResponse(http.StatusBadRequest))
+			op.AddResponse(http.StatusUnprocessableEntity, errorResponse(http.StatusUnprocessableEntity))
+			op.AddResponse(http.StatusNotFound, errorResponse(http.StatusNotFound))
+		case resource.OperationDelete:
+			op.OperationID = "delete" + name
+			op.Summary = "Delete a " + d.Name
+			op.AddResponse(http.StatusNoContent, openapi3.NewResponse().WithDescription(http.StatusText(http.StatusNoContent)))
+			op.AddResponse(http.StatusNotFound, errorResponse(http.StatusNotFound))
+		case resource.OperationBatchCreate, resource.OperationBatchUpdate, resource.OperationBatchDelete:
+			b.addBatchOperation(op, d, route.Operation, input, item, errorResponse)
+		default:
+			// subresources are plain http handlers, so there is nothing to describe
+			continue
+		}
+		op.Responses.Set("default", &openapi3.ResponseRef{Value: errorResponse(0)})
+
+		// the ids of the item and of its parents
+		for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+			op.AddParameter(openapi3.NewPathParameter(match[1]).WithSchema(openapi3.NewStringSchema()))
+		}
+		if d.Versioned {
+			addConditionalHeaders(op, route.Operation, errorResponse)
+		}
+		if len(d.Security) > 0 {
+			b.addSecurity(op, d.Security)
+			op.AddResponse(http.StatusUnauthorized, errorResponse(http.StatusUnauthorized))
+		}
+		if d.Authorized {
+			op.AddResponse(http.StatusForbidden, errorResponse(http.StatusForbidden))
+		}
+		if d.Parent != "" {
+			// the parent item may not exist
+			op.AddResponse(http.StatusNotFound, errorResponse(http.StatusNotFound))
+		}
+		if d.MaxBodySize > 0 && op.RequestBody != nil {
+			op.AddResponse(http.StatusRequestEntityTooLarge, errorResponse(http.StatusRequestEntityTooLarge))
+		}
+		addMediaTypes(op, d.MediaTypes)
+		b.addOperation(route.Path, route.Method, op)
+	}
+
+	for _, nested := range d.Nested {
+		b.addResource(nested)
+	}
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func addListQueryParameters(op *openapi3.Operation, d resource.Description) {
+	if len(d.Filterable) > 0 {
+		op.AddParameter(openapi3.NewQueryParameter("filter").
+			WithDescription(`Conditions joined by "and", e.g. name eq "value". Filterable fields: ` + strings.Join(d.Filterable, ", ")).
+			WithSchema(openapi3.NewStringSchema()))
+		for _, field := range d.Filterable {
+			if op.Parameters.GetByInAndName("query", field) == nil {
+				op.AddParameter(openapi3.NewQueryParameter(field).
+					WithDescription("Shorthand for filter=" + field + " eq <value>").
+					WithSchema(openapi3.NewStringSchema()))
+			}
+		}
+	}
+	if len(d.Sortable) > 0 {
+		op.AddParameter(openapi3.NewQueryParameter("sort").
+			WithDescription("Comma separated fields, prefixed with - for descending order. Sortable fields: " + strings.Join(d.Sortable, ", ")).
+			WithSchema(openapi3.NewStringSchema()))
+	}
+	if len(d.Selectable) > 0 {
+		op.AddParameter(fieldsParameter(d.Selectable))
+	}
+}
+
+// addMediaTypes offers the JSON schemas of op in every media type of the resource.
+func addMediaTypes(op *openapi3.Operation, mediaTypes []string) {
+	expand := func(content openapi3.Content) {
+		json := content.Get(resource.MIMEApplicationJSON)
+		if json == nil {
+			return
+		}
+		for _, mediaType := range mediaTypes {
+			if content.Get(mediaType) == nil {
+				content[mediaType] = openapi3.NewMediaType().WithSchemaRef(json.Schema)
+			}
+		}
+	}
+	if op.RequestBody != nil {
+		expand(op.RequestBody.Value.Content)
+	}
+	for _, res := range op.Responses.Map() {
+		expand(res.Value.Content)
+	}
+}
+
+// addSecurity registers the schemes as components, and requires any one of them.
+func (b *builder) addSecurity(op *openapi3.Operation, schemes []resource.SecurityScheme) {
+	security := openapi3.NewSecurityRequirements()
+	for _, scheme := range schemes {
+		b.securitySchemes[scheme.Name] = &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{
+			Type:         scheme.Type,
+			Scheme:       scheme.Scheme,
+			BearerFormat: scheme.BearerFormat,
+			In:           scheme.In,
+			Name:         scheme.ParamName,
+		}}
+		security.With(openapi3.NewSecurityRequirement().Authenticate(scheme.Name))
+	}
+	op.Security = security
+}
+
+// addConditionalHeaders documents the ETag based preconditions of versioned resources.
+func addConditionalHeaders(op *openapi3.Operation, operation resource.Operation, errorResponse func(status int) *openapi3.Response) {
+	etag := openapi3.Headers{
+		resource.HeaderETag: &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+			Description: "Version of the returned resource",
+			Schema:      openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+		}}},
+	}
+	for _, status := range []int{http.StatusOK, http.StatusCreated} {
+		if res := op.Responses.Status(status); res != nil {
+			res.Value.Headers = etag
+		}
+	}
+
+	switch operation {
+	case resource.OperationGet:
+		op.AddParameter(openapi3.NewHeaderParameter(resource.HeaderIfNoneMatch).WithSchema(openapi3.NewStringSchema()))
+		op.AddResponse(http.StatusNotModified, openapi3.NewResponse().WithDescription(http.StatusText(http.StatusNotModified)))
+	case resource.OperationUpdate, resource.OperationPatch, resource.OperationDelete:
+		op.AddParameter(openapi3.NewHeaderParameter(resource.HeaderIfMatch).WithSchema(openapi3.NewStringSchema()))
+		op.AddResponse(http.StatusPreconditionFailed, errorResponse(http.StatusPreconditionFailed))
+	}
+}
+
+func fieldsParameter(selectable []string) *openapi3.Parameter {
+	return openapi3.NewQueryParameter("fields").
+		WithDescription("Comma separated fields to return. Selectable fields: " + strings.Join(selectable, ", ")).
+		WithSchema(openapi3.NewStringSchema())
+}
+
+func (b *builder) addOperation(path string, method string, op *openapi3.Operation) {
+	pathItem := b.paths.Value(path)
+	if pathItem == nil {
+		pathItem = &openapi3.PathItem{}
+		b.paths.Set(path, pathItem)
+	}
+	pathItem.SetOperation(method, op)
+}
+
+// listSchemaRef registers the ResourceList envelope for d, e.g. "mockList".
+func (b *builder) listSchemaRef(d resource.Description, item *openapi3.SchemaRef) *openapi3.SchemaRef {
+	name := camelCase(d.Name + "List")
+	schema := openapi3.NewObjectSchema()
+	items := openapi3.NewArraySchema()
+	items.Items = item
+	schema.Properties["items"] = openapi3.NewSchemaRef("", items)
+	schema.Properties["metadata"] = b.schemaRefFor(reflect.TypeFor[resource.Metadata]())
+	schema.Required = []string{"items", "metadata"}
+
+	b.schemas[name] = openapi3.NewSchemaRef("", schema)
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+}
+
+// addBatchOperation documents a batch endpoint, whose request and
+// BatchResponse schemas are registered as e.g. "mockBatchCreate" and "mockBatchResponse".
+func (b *builder) addBatchOperation(op *openapi3.Operation, d resource.Description, operation resource.Operation,
+	input *openapi3.SchemaRef, item *openapi3.SchemaRef, errorResponse func(status int) *openapi3.Response) {
+	op.OperationID = string(operation) + upperFirst(d.Parent) + upperFirst(d.Plural)
+	items := openapi3.NewArraySchema()
+	request := openapi3.NewObjectSchema()
+	switch operation {
+	case resource.OperationBatchCreate:
+		op.Summary = "Create " + d.Plural + " in a batch"
+		items.Items = input
+		request.Properties["items"] = openapi3.NewSchemaRef("", items)
+		request.Required = []string{"items"}
+	case resource.OperationBatchUpdate:
+		op.Summary = "Update " + d.Plural + " in a batch"
+		update := openapi3.NewObjectSchema()
+		update.Properties["id"] = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+		update.Properties["item"] = input
+		update.Required = []string{"id", "item"}
+		items.Items = openapi3.NewSchemaRef("", update)
+		request.Properties["items"] = openapi3.NewSchemaRef("", items)
+		request.Required = []string{"items"}
+	case resource.OperationBatchDelete:
+		op.Summary = "Delete " + d.Plural + " in a batch"
+		items.Items = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+		request.Properties["ids"] = openapi3.NewSchemaRef("", items)
+		request.Required = []string{"ids"}
+	}
+	requestName := camelCase(d.Name + upperFirst(string(operation)))
+	b.schemas[requestName] = openapi3.NewSchemaRef("", request)
+	op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).
+		WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/"+requestName, nil))}
+
+	result := openapi3.NewObjectSchema()
+	result.Properties["status"] = openapi3.NewSchemaRef("", openapi3.NewIntegerSchema())
+	result.Properties["id"] = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+	result.Properties["item"] = item
+	result.Properties["error"] = b.schemaRefFor(reflect.TypeFor[resource.ErrorResponse]())
+	result.Required = []string{"status"}
+	response := openapi3.NewObjectSchema()
+	response.Properties["items"] = openapi3.NewSchemaRef("", openapi3.NewArraySchema().WithItems(result))
+	response.Required = []string{"items"}
+	responseName := camelCase(d.Name + "BatchResponse")
+	b.schemas[responseName] = openapi3.NewSchemaRef("", response)
+	responseRef := openapi3.NewSchemaRef("#/components/schemas/"+responseName, nil)
+
+	op.AddResponse(http.StatusOK, jsonResponse("Every item succeeded", responseRef))
+	if d.PartialBatches {
+		op.AddResponse(http.StatusMultiStatus, jsonResponse("Some items failed", responseRef))
+	} else {
+		op.AddResponse(http.StatusUnprocessableEntity, jsonResponse("An item failed, so none was processed", responseRef))
+	}
+	op.AddResponse(http.StatusBadRequest, errorResponse(http.StatusBadRequest))
+}
+
+// inputSchemaRef returns the schema of the request bodies carrying an item.
+// Ids are usually assigned by the server, so when the item schema requires
+// an "id" member, a copy which does not is registered as e.g. "mockInput".
+func (b *builder) inputSchemaRef(item *openapi3.SchemaRef) *openapi3.SchemaRef {
+	name := strings.TrimPrefix(item.Ref, "#/components/schemas/")
+	schema := b.schemas[name]
+	if item.Ref == "" || schema == nil || !slices.Contains(schema.Value.Required, "id") {
+		return item
+	}
+	input := *schema.Value
+	input.Required = slices.DeleteFunc(slices.Clone(input.Required), func(s string) bool { return s == "id" })
+	b.schemas[name+"Input"] = openapi3.NewSchemaRef("", &input)
+	return openapi3.NewSchemaRef("#/components/schemas/"+name+"Input", nil)
+}
+
+// jsonPatchSchemaRef registers the RFC 6902 document schema as "jsonPatch".
+func (b *builder) jsonPatchSchemaRef() *openapi3.SchemaRef {
+	operation := openapi3.NewObjectSchema()
+	operation.Properties["op"] = openapi3.NewSchemaRef("", openapi3.NewStringSchema().WithEnum("add", "remove", "replace", "move", "copy", "test"))
+	operation.Properties["path"] = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+	operation.Properties["from"] = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+	operation.Properties["value"] = openapi3.NewSchemaRef("", openapi3.NewSchema())
+	operation.Required = []string{"op", "path"}
+
+	b.schemas["jsonPatch"] = openapi3.NewSchemaRef("", openapi3.NewArraySchema().WithItems(operation))
+	return openapi3.NewSchemaRef("#/components/schemas/jsonPatch", nil)
+}
+
+// errorResponseFunc returns a constructor of the error responses of d, which
+// are either ErrorResponse or RFC 9457 problem details.
+func (b *builder) errorResponseFunc(d resource.Description) func(status int) *openapi3.Response {
+	mediaType := resource.MIMEApplicationJSON
+	schema := b.schemaRefFor(reflect.TypeFor[resource.ErrorResponse]())
+	if d.ProblemDetails {
+		mediaType = resource.MIMEApplicationProblemJSON
+		schema = b.schemaRefFor(reflect.TypeFor[resource.ProblemDetails]())
+		// extension members are allowed next to the standard ones
+		b.schemas["problemDetails"].Value.AdditionalProperties = openapi3.AdditionalProperties{Has: openapi3.BoolPtr(true)}
+	}
+	return func(status int) *openapi3.Response {
+		description := "Error"
+		if status != 0 {
+			description = http.StatusText(status)
+		}
+		return openapi3.NewResponse().WithDescription(description).WithContent(openapi3.Content{
+			mediaType: openapi3.NewMediaType().WithSchemaRef(schema),
+		})
+	}
+}
+
+func jsonResponse(description string, schema *openapi3.SchemaRef) *openapi3.Response {
+	return openapi3.NewResponse().WithDescription(description).WithJSONSchemaRef(schema)
+}
+
+func upperFirst(s string) string {
+	s = camelCase(s)
+	if len(s) > 0 {
+		s = strings.ToUpper(s[:1]) + s[1:]
+	}
+	return s
+}
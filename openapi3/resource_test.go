@@ -0,0 +1,252 @@
+package openapi3_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	kin "github.com/getkin/kin-openapi/openapi3"
+	"github.com/iwanhae/resource"
+	"github.com/iwanhae/resource/openapi3"
+)
+
+type MockResource struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (m MockResource) ValidateCreate(ctx resource.Context) error            { return nil }
+func (m MockResource) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func newMockResource() *resource.Resource[MockResource] {
+	return resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		List(func(ctx resource.Context, query resource.ListQuery) ([]MockResource, error) { return nil, nil }).
+		Create(func(ctx resource.Context, r MockResource) (MockResource, error) { return r, nil }).
+		Get(func(ctx resource.Context, id string) (MockResource, error) { return MockResource{}, nil }).
+		Update(func(ctx resource.Context, id string, r MockResource) (MockResource, error) { return r, nil }).
+		Patch(func(ctx resource.Context, id string, r MockResource) (MockResource, error) { return r, nil }).
+		Delete(func(ctx resource.Context, id string) error { return nil })
+}
+
+func TestResourceDocument(t *testing.T) {
+	doc := openapi3.NewBuilder().
+		Info("mock", "1.0.0").
+		Resource(newMockResource()).
+		Build()
+
+	testCases := []struct {
+		name   string
+		got    interface{}
+		expect interface{}
+	}{
+		{
+			name:   "list operation",
+			got:    doc.Paths.Value("/mocks").Get.OperationID,
+			expect: "listMocks",
+		},
+		{
+			name:   "create operation",
+			got:    doc.Paths.Value("/mocks").Post.OperationID,
+			expect: "createMock",
+		},
+		{
+			name:   "get operation has path parameter",
+			got:    doc.Paths.Value("/mocks/{mockId}").Get.Parameters.GetByInAndName("path", "mockId") != nil,
+			expect: true,
+		},
+		{
+			name:   "create does not require the id",
+			got:    doc.Paths.Value("/mocks").Post.RequestBody.Value.Content.Get("application/json").Schema.Ref,
+			expect: "#/components/schemas/mockResourceInput",
+		},
+		{
+			name:   "input schema omits the id from required",
+			got:    strings.Join(doc.Components.Schemas["mockResourceInput"].Value.Required, ","),
+			expect: "name",
+		},
+		{
+			name:   "error detail is nullable",
+			got:    doc.Components.Schemas["errorResponse"].Value.Properties["error"].Value.Nullable,
+			expect: true,
+		},
+		{
+			name:   "patch accepts json patch",
+			got:    doc.Paths.Value("/mocks/{mockId}").Patch.RequestBody.Value.Content.Get("application/json-patch+json").Schema.Ref,
+			expect: "#/components/schemas/jsonPatch",
+		},
+		{
+			name:   "list has limit parameter",
+			got:    doc.Paths.Value("/mocks").Get.Parameters.GetByInAndName("query", "limit") != nil,
+			expect: true,
+		},
+		{
+			name:   "limit parameter is capped",
+			got:    *doc.Paths.Value("/mocks").Get.Parameters.GetByInAndName("query", "limit").Schema.Value.Max,
+			expect: 1000.0,
+		},
+		{
+			name:   "list responds with envelope",
+			got:    doc.Paths.Value("/mocks").Get.Responses.Status(200).Value.Content.Get("application/json").Schema.Ref,
+			expect: "#/components/schemas/mockList",
+		},
+		{
+			name:   "errors respond with ErrorResponse",
+			got:    doc.Paths.Value("/mocks/{mockId}").Get.Responses.Status(404).Value.Content.Get("application/json").Schema.Ref,
+			expect: "#/components/schemas/errorResponse",
+		},
+	}
+	for _, tc := range testCases {
+		if tc.got != tc.expect {
+			t.Errorf("%s: Expect %v but got %v", tc.name, tc.expect, tc.got)
+		}
+	}
+
+	// the generated document must be a valid spec
+	raw, err := json.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := kin.NewLoader().LoadFromData(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Validate(context.Background()); err != nil {
+		t.Errorf("invalid document: %v", err)
+	}
+}
+
+func TestResourceSecurity(t *testing.T) {
+	r := newMockResource().Authenticate(resource.JWTAuth([]byte("secret")))
+	doc := openapi3.NewBuilder().Resource(r).Build()
+
+	scheme := doc.Components.SecuritySchemes["bearerAuth"]
+	if scheme == nil || scheme.Value.Scheme != "bearer" || scheme.Value.BearerFormat != "JWT" {
+		t.Fatalf("unexpected security scheme: %+v", scheme)
+	}
+	op := doc.Paths.Value("/mocks").Get
+	if op.Security == nil || len(*op.Security) != 1 {
+		t.Fatalf("expected operation to require the bearer scheme")
+	}
+	if op.Responses.Status(401) == nil {
+		t.Errorf("expected a 401 response")
+	}
+}
+
+func TestResourceProblemDetails(t *testing.T) {
+	doc := openapi3.NewBuilder().Resource(newMockResource().ProblemDetails(true)).Build()
+
+	res := doc.Paths.Value("/mocks/{mockId}").Get.Responses.Status(404)
+	content := res.Value.Content.Get("application/problem+json")
+	if content == nil || content.Schema.Ref != "#/components/schemas/problemDetails" {
+		t.Fatalf("expected errors to respond with problem details, got %+v", res.Value.Content)
+	}
+	if doc.Components.Schemas["problemDetails"] == nil {
+		t.Errorf("expected problemDetails schema")
+	}
+}
+
+type TaggedResource struct {
+	Name string `json:"name,omitempty" validate:"required,max=64"`
+	Role string `json:"role" validate:"enum=admin|member"`
+	Age  int    `json:"age" validate:"min=0,max=150,enum=1|2"`
+	Code string `json:"code" validate:"pattern=^[A-Z]{3}$"`
+}
+
+func TestRegisterRules(t *testing.T) {
+	b := openapi3.NewBuilder()
+	b.Register(reflect.TypeFor[TaggedResource]())
+	schema := b.Build().Components.Schemas["taggedResource"].Value
+
+	if !slices.Contains(schema.Required, "name") {
+		t.Errorf("expected name to be required, got %v", schema.Required)
+	}
+	if max := schema.Properties["name"].Value.MaxLength; max == nil || *max != 64 {
+		t.Errorf("expected name maxLength 64, got %v", max)
+	}
+	if enum := schema.Properties["role"].Value.Enum; len(enum) != 2 || enum[0] != "admin" {
+		t.Errorf("unexpected role enum %v", enum)
+	}
+	age := schema.Properties["age"].Value
+	if age.Min == nil || *age.Min != 0 || age.Max == nil || *age.Max != 150 || age.Enum[0] != float64(1) {
+		t.Errorf("unexpected age constraints %+v", age)
+	}
+	if pattern := schema.Properties["code"].Value.Pattern; pattern != "^[A-Z]{3}$" {
+		t.Errorf("unexpected code pattern %v", pattern)
+	}
+}
+
+func TestResourceBatch(t *testing.T) {
+	r := newMockResource().
+		BatchCreate(func(ctx resource.Context, items []MockResource) ([]MockResource, error) { return items, nil }).
+		BatchDelete(func(ctx resource.Context, ids []string) error { return nil })
+	doc := openapi3.NewBuilder().Resource(r).Build()
+
+	op := doc.Paths.Value("/mocks:batchCreate").Post
+	if op.OperationID != "batchCreateMocks" {
+		t.Errorf("unexpected operation id %v", op.OperationID)
+	}
+	if ref := op.Responses.Status(200).Value.Content.Get("application/json").Schema.Ref; ref != "#/components/schemas/mockBatchResponse" {
+		t.Errorf("unexpected response schema %v", ref)
+	}
+	if doc.Paths.Value("/mocks:batchDelete").Post.RequestBody.Value.Content.Get("application/json").Schema.Ref != "#/components/schemas/mockBatchDelete" {
+		t.Errorf("unexpected batch delete request schema")
+	}
+
+	raw, err := json.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := kin.NewLoader().LoadFromData(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Validate(context.Background()); err != nil {
+		t.Errorf("invalid document: %v", err)
+	}
+}
+
+func TestResourceNested(t *testing.T) {
+	children := resource.New[MockResource]().
+		Name("child").
+		Plural("children").
+		List(func(ctx resource.Context, query resource.ListQuery) ([]MockResource, error) { return nil, nil })
+	doc := openapi3.NewBuilder().Resource(newMockResource().Nest(children)).Build()
+
+	op := doc.Paths.Value("/mocks/{mockId}/children").Get
+	if op == nil || op.OperationID != "listMockChildren" {
+		t.Fatalf("expected the nested list operation, got %+v", op)
+	}
+	if op.Parameters.GetByInAndName("path", "mockId") == nil {
+		t.Errorf("expected the parent id parameter")
+	}
+	if op.Responses.Status(404) == nil {
+		t.Errorf("expected a 404 response for missing parents")
+	}
+}
+
+func TestGroupDocument(t *testing.T) {
+	v1 := resource.NewGroup("/api/v1").Add(newMockResource()).Discovery(true)
+	v2 := resource.NewGroup("/api/v2").Add(newMockResource()).ProblemDetails(true)
+	doc := openapi3.NewBuilder().Group(v1, v2).Build()
+
+	for _, path := range []string{"/api/v1/mocks", "/api/v2/mocks/{mockId}", "/api/v1/"} {
+		if doc.Paths.Value(path) == nil {
+			t.Errorf("expected path %s in the document", path)
+		}
+	}
+	if doc.Paths.Value("/api/v2/") != nil {
+		t.Errorf("expected no discovery path for the group without discovery")
+	}
+	res := doc.Paths.Value("/api/v2/mocks/{mockId}").Get.Responses.Status(404).Value
+	if res.Content.Get(resource.MIMEApplicationProblemJSON) == nil {
+		t.Errorf("expected the group's problem details in the error responses")
+	}
+	if _, ok := doc.Components.Schemas["aPIResourceList"]; !ok {
+		t.Errorf("expected the discovery schema")
+	}
+}
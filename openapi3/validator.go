@@ -0,0 +1,203 @@
+package openapi3
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/iwanhae/resource"
+)
+
+// Validator checks requests, and optionally responses, against a document
+// built by the builder. Use its Middleware on the documented resources,
+// after authentication so that only authorized callers learn the schemas:
+//
+//	doc := openapi3.NewBuilder().Resource(users).Build()
+//	v, err := openapi3.NewValidator(&doc)
+//	users.UseAfterAuth(v.Middleware)
+//
+// Invalid bodies are rejected with 422 and invalid parameters with 400,
+// before the Validator methods of the resource type run. Merge patches are
+// only checked to be objects, as they hold part of an item. Requests on
+// routes missing from the document, such as subresources, are not validated.
+type Validator struct {
+	router            routers.Router
+	validateResponses bool
+}
+
+func init() {
+	// kin-openapi decodes json and json patch bodies, but not merge patches
+	if openapi3filter.RegisteredBodyDecoder(resource.MIMEApplicationMergePatchJSON) == nil {
+		openapi3filter.RegisterBodyDecoder(resource.MIMEApplicationMergePatchJSON, openapi3filter.JSONBodyDecoder)
+	}
+}
+
+func NewValidator(doc *openapi3.T) (*Validator, error) {
+	// the builder leaves references unresolved, loading resolves them
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := openapi3.NewLoader().LoadFromData(raw)
+	if err != nil {
+		return nil, err
+	}
+	router, err := legacy.NewRouter(loaded)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router}, nil
+}
+
+// ValidateResponses makes invalid responses fail with 500 Internal Server
+// Error. It buffers every response, so it is meant for development and tests.
+func (v *Validator) ValidateResponses(enabled bool) *Validator {
+	v.validateResponses = enabled
+	return v
+}
+
+// Middleware validates the requests of next. It matches resource.Middleware.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				ExcludeRequestBody: !hasBodyDecoder(r.Header.Get(resource.HeaderContentType)),
+			},
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			resource.WriteError(w, http.StatusBadRequest, requestError(err))
+			return
+		}
+		if !v.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, header: make(http.Header)}
+		next.ServeHTTP(rec, r)
+		res := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 rec.status,
+			Header:                 rec.header,
+			Options: &openapi3filter.Options{
+				IncludeResponseStatus: true,
+				ExcludeResponseBody:   !hasBodyDecoder(rec.header.Get(resource.HeaderContentType)),
+			},
+		}
+		if err := openapi3filter.ValidateResponse(r.Context(), res.SetBodyBytes(rec.body.Bytes())); err != nil {
+			resource.Context{Context: r.Context()}.Logger().Error("invalid response", "error", err)
+			resource.WriteError(w, http.StatusInternalServerError, resource.ErrInternal.WithMessage("invalid response").Wrap(err))
+			return
+		}
+		for k, values := range rec.header {
+			w.Header()[k] = values
+		}
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+func hasBodyDecoder(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && openapi3filter.RegisteredBodyDecoder(mediaType) != nil
+}
+
+// requestError converts the errors of openapi3filter to field errors.
+func requestError(err error) error {
+	var body, params resource.ValidationError
+	var other error
+	var walk func(err error, param *openapi3.Parameter)
+	walk = func(err error, param *openapi3.Parameter) {
+		switch e := err.(type) {
+		case openapi3.MultiError:
+			for _, err := range e {
+				walk(err, param)
+			}
+		case *openapi3filter.RequestError:
+			if e.Parameter != nil {
+				param = e.Parameter
+			}
+			var me openapi3.MultiError
+			var se *openapi3.SchemaError
+			switch {
+			case errors.As(e.Err, &me):
+				walk(me, param)
+			case errors.As(e.Err, &se):
+				walk(se, param)
+			case param != nil:
+				params.Errors = append(params.Errors, resource.FieldError{Parameter: param.Name, Message: e.Error()})
+			default:
+				other = e
+			}
+		case *openapi3.SchemaError:
+			if param != nil {
+				params.Errors = append(params.Errors, resource.FieldError{Parameter: param.Name, Code: e.SchemaField, Message: e.Reason})
+				return
+			}
+			var tokens []any
+			for _, token := range e.JSONPointer() {
+				tokens = append(tokens, token)
+			}
+			body.Errors = append(body.Errors, resource.FieldError{Pointer: resource.Pointer(tokens...), Code: e.SchemaField, Message: e.Reason})
+		default:
+			other = err
+		}
+	}
+	walk(err, nil)
+
+	switch {
+	case len(params.Errors) > 0:
+		return resource.ErrBadRequest.Wrap(&params)
+	case len(body.Errors) > 0:
+		return &body
+	case other != nil:
+		return resource.ErrBadRequest.Wrap(other)
+	}
+	return resource.ErrBadRequest.Wrap(err)
+}
+
+// responseRecorder buffers a response until it is validated. It unwraps to
+// the original writer, so that the negotiated codec is still used.
+type responseRecorder struct {
+	http.ResponseWriter
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
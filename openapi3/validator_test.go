@@ -0,0 +1,149 @@
+package openapi3_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iwanhae/resource"
+	"github.com/iwanhae/resource/openapi3"
+)
+
+type Animal struct {
+	ID   string   `json:"id"`
+	Name string   `json:"name" validate:"max=8"`
+	Age  int      `json:"age" validate:"min=0"`
+	Tags []string `json:"tags"`
+}
+
+func (a Animal) ValidateCreate(ctx resource.Context) error            { return nil }
+func (a Animal) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func TestValidator(t *testing.T) {
+	animals := resource.New[Animal]().
+		Name("animal").
+		Plural("animals").
+		List(func(ctx resource.Context, query resource.ListQuery) ([]Animal, error) { return nil, nil }).
+		Create(func(ctx resource.Context, a Animal) (Animal, error) {
+			a.ID = "1"
+			return a, nil
+		}).
+		Get(func(ctx resource.Context, id string) (Animal, error) {
+			switch id {
+			case "1":
+				return Animal{ID: id, Name: "rex"}, nil
+			case "2":
+				return Animal{ID: id, Name: "much too long", Age: 1}, nil
+			}
+			return Animal{}, resource.ErrNotFound.WithMessage("no such animal")
+		})
+	doc := openapi3.NewBuilder().Resource(animals).Build()
+	v, err := openapi3.NewValidator(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	animals.UseAfterAuth(v.ValidateResponses(true).Middleware)
+
+	testCases := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		expectedStatus int
+		expectedErrors []resource.FieldError
+	}{
+		{"ValidBody", "POST", "/animals", `{"name":"rex","age":3,"tags":null}`, http.StatusCreated, nil},
+		{"InvalidBody", "POST", "/animals", `{"name":"much too long","age":"old","tags":["a"]}`, http.StatusUnprocessableEntity, []resource.FieldError{
+			{Pointer: "/age", Code: "type", Message: "value must be an integer"},
+			{Pointer: "/name", Code: "maxLength", Message: "maximum string length is 8"},
+		}},
+		{"InvalidQuery", "GET", "/animals?limit=many", "", http.StatusBadRequest, nil},
+		{"ValidItem", "GET", "/animals/1", "", http.StatusOK, nil},
+		{"NotFound", "GET", "/animals/9", "", http.StatusNotFound, nil},
+		{"InvalidResponse", "GET", "/animals/2", "", http.StatusInternalServerError, nil},
+		{"ValidResponse", "GET", "/animals", "", http.StatusOK, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, bytes.NewBufferString(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			animals.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			if tc.expectedErrors == nil {
+				return
+			}
+			var errResp struct {
+				Error []resource.FieldError `json:"error"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+				t.Fatalf("Could not decode error response: %v", err)
+			}
+			got, _ := json.Marshal(errResp.Error)
+			expected, _ := json.Marshal(tc.expectedErrors)
+			if string(got) != string(expected) {
+				t.Errorf("Unexpected field errors: got %s want %s", got, expected)
+			}
+		})
+	}
+}
+
+func TestValidatorAfterAuth(t *testing.T) {
+	animals := resource.New[Animal]().
+		Name("animal").
+		Plural("animals").
+		Get(func(ctx resource.Context, id string) (Animal, error) { return Animal{ID: id, Name: "rex"}, nil }).
+		Create(func(ctx resource.Context, a Animal) (Animal, error) { return a, nil }).
+		Patch(func(ctx resource.Context, id string, a Animal) (Animal, error) { return a, nil }).
+		Authenticate(resource.APIKeyAuth("X-API-Key", func(ctx context.Context, key string) (*resource.Principal, error) {
+			return &resource.Principal{Subject: key}, nil
+		}))
+	doc := openapi3.NewBuilder().Resource(animals).Build()
+	v, err := openapi3.NewValidator(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	animals.UseAfterAuth(v.Middleware)
+
+	testCases := []struct {
+		name           string
+		method         string
+		target         string
+		contentType    string
+		body           string
+		apiKey         string
+		expectedStatus int
+	}{
+		{"InvalidBodyAnonymous", "POST", "/animals", "application/json", `{"age":"old"}`, "", http.StatusUnauthorized},
+		{"InvalidBody", "POST", "/animals", "application/json", `{"age":"old"}`, "key", http.StatusUnprocessableEntity},
+		{"InvalidMergePatch", "PATCH", "/animals/1", "application/merge-patch+json", `[1]`, "key", http.StatusUnprocessableEntity},
+		{"MergePatch", "PATCH", "/animals/1", "application/merge-patch+json", `{"age":3}`, "key", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			if tc.apiKey != "" {
+				req.Header.Set("X-API-Key", tc.apiKey)
+			}
+			rr := httptest.NewRecorder()
+			animals.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package resource
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// applyPatch applies a JSON Merge Patch (RFC 7386) or a JSON Patch (RFC 6902)
+// to current, depending on contentType, and decodes the result into out.
+// Plain application/json is treated as a merge patch. With strict set, the
+// patch must not have duplicate keys, nor introduce unknown fields.
+func applyPatch(contentType string, current any, patch []byte, out any, strict bool) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = MIMEApplicationJSON
+	}
+
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeJSON(raw)
+	if err != nil {
+		return err
+	}
+
+	if strict {
+		if err := checkDuplicateKeys(json.NewDecoder(bytes.NewReader(patch)), ""); err != nil {
+			return fmt.Errorf("invalid patch: %w", err)
+		}
+	}
+
+	switch mediaType {
+	case MIMEApplicationMergePatchJSON, MIMEApplicationJSON:
+		p, err := decodeJSON(patch)
+		if err != nil {
+			return fmt.Errorf("invalid merge patch: %w", err)
+		}
+		doc = mergePatch(doc, p)
+	case MIMEApplicationJSONPatchJSON:
+		var ops []patchOperation
+		if err := json.Unmarshal(patch, &ops); err != nil {
+			return fmt.Errorf("invalid json patch: %w", err)
+		}
+		if doc, err = jsonPatch(doc, ops); err != nil {
+			return err
+		}
+	default:
+		return ErrUnsupportedMediaType.WithMessage(fmt.Sprintf("unsupported patch media type %q", mediaType))
+	}
+
+	raw, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	unmarshal := json.Unmarshal
+	if strict {
+		unmarshal = unmarshalStrict
+	}
+	if err := unmarshal(raw, out); err != nil {
+		return ErrUnprocessable.Wrap(err)
+	}
+	return nil
+}
+
+// patchTarget returns the value a patched document is decoded into: a copy
+// of current whose JSON members are reset, so that members removed by the
+// patch end up zero, while fields hidden from JSON, such as json:"-" and
+// unexported ones, keep their current value. Types other than structs are
+// decoded into their zero value.
+func patchTarget[T any](current T) T {
+	v := reflect.ValueOf(&current).Elem()
+	if v.Kind() != reflect.Struct {
+		var zero T
+		return zero
+	}
+	resetJSONFields(v)
+	return current
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// resetJSONFields zeroes the fields of the struct v which encoding/json
+// decodes, descending into embedded and nested structs.
+func resetJSONFields(v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		field := v.Field(i)
+		switch {
+		case f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct:
+			resetJSONFields(field)
+		case !f.IsExported():
+			// embedded pointers to unexported structs are skipped by encoding/json too
+		case f.Type.Kind() == reflect.Struct && !decodesItself(f.Type):
+			resetJSONFields(field)
+		default:
+			// pointers are shared with current, so they are replaced rather than descended into
+			field.SetZero()
+		}
+	}
+}
+
+func decodesItself(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+	return p.Implements(jsonUnmarshalerType) || p.Implements(textUnmarshalerType)
+}
+
+func decodeJSON(raw []byte) (any, error) {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func mergePatch(target any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+func jsonPatch(doc any, ops []patchOperation) (any, error) {
+	for i, op := range ops {
+		var err error
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("json patch operation %d (%s %q): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func (op patchOperation) apply(doc any) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, ErrBadRequest.Wrap(err)
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, ErrBadRequest.WithMessage("missing value")
+		}
+		value, err := decodeJSON(op.Value)
+		if err != nil {
+			return nil, ErrBadRequest.Wrap(err)
+		}
+		switch op.Op {
+		case "add":
+			return pointerAdd(doc, path, value)
+		case "replace":
+			if doc, _, err = pointerRemove(doc, path); err != nil {
+				return nil, err
+			}
+			return pointerAdd(doc, path, value)
+		default:
+			current, err := pointerGet(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, ErrConflict.WithMessage("test failed")
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err = pointerRemove(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, ErrBadRequest.Wrap(err)
+		}
+		var value any
+		if op.Op == "move" {
+			if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+				return nil, ErrUnprocessable.WithMessage("cannot move a value into one of its children")
+			}
+			if doc, value, err = pointerRemove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			if value, err = pointerGet(doc, from); err != nil {
+				return nil, err
+			}
+			if value, err = deepCopy(value); err != nil {
+				return nil, err
+			}
+		}
+		return pointerAdd(doc, path, value)
+	}
+	return nil, ErrBadRequest.WithMessage(fmt.Sprintf("unknown operation %q", op.Op))
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func pointerGet(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[token]
+			if !ok {
+				return nil, ErrUnprocessable.WithMessage(fmt.Sprintf("member %q not found", token))
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, ErrUnprocessable.WithMessage(fmt.Sprintf("cannot traverse into %q", token))
+		}
+	}
+	return doc, nil
+}
+
+// pointerUpdate replaces the container holding the last token of path with
+// the result of f, rebuilding every parent on the way back up.
+func pointerUpdate(doc any, path []string, f func(container any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return f(doc, path[0])
+	}
+	child, err := pointerGet(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = pointerUpdate(child, path[1:], f)
+	if err != nil {
+		return nil, err
+	}
+	switch node := doc.(type) {
+	case map[string]any:
+		node[path[0]] = child
+	case []any:
+		i, _ := strconv.Atoi(path[0])
+		node[i] = child
+	}
+	return doc, nil
+}
+
+func pointerAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return pointerUpdate(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			if token == "-" {
+				return append(node, value), nil
+			}
+			i, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		return nil, ErrUnprocessable.WithMessage(fmt.Sprintf("cannot add %q to a scalar", token))
+	})
+}
+
+func pointerRemove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	var removed any
+	doc, err := pointerUpdate(doc, path, func(container any, token string) (any, error) {
+		switch node := container.(type) {
+		case map[string]any:
+			v, ok := node[token]
+			if !ok {
+				return nil, ErrUnprocessable.WithMessage(fmt.Sprintf("member %q not found", token))
+			}
+			removed = v
+			delete(node, token)
+			return node, nil
+		case []any:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[i]
+			return append(node[:i], node[i+1:]...), nil
+		}
+		return nil, ErrUnprocessable.WithMessage(fmt.Sprintf("cannot remove %q from a scalar", token))
+	})
+	return doc, removed, err
+}
+
+func arrayIndex(token string, last int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > last || (len(token) > 1 && token[0] == '0') {
+		return 0, ErrUnprocessable.WithMessage(fmt.Sprintf("invalid array index %q", token))
+	}
+	return i, nil
+}
+
+func deepCopy(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, ErrUnprocessable.Wrap(err)
+	}
+	return decodeJSON(raw)
+}
@@ -0,0 +1,132 @@
+package resource_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type PatchableResource struct {
+	ID   string   `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func (p PatchableResource) ValidateCreate(ctx resource.Context) error { return nil }
+
+func (p PatchableResource) ValidateUpdate(ctx resource.Context, id string) error {
+	return MockResource{Name: p.Name}.ValidateCreate(ctx)
+}
+
+func TestPatch(t *testing.T) {
+	r := resource.New[PatchableResource]().
+		Name("mock").
+		Plural("mocks").
+		Get(func(ctx resource.Context, id string) (PatchableResource, error) {
+			return PatchableResource{ID: id, Name: "Test", Tags: []string{"a", "b"}}, nil
+		}).
+		Patch(func(ctx resource.Context, id string, p PatchableResource) (PatchableResource, error) {
+			return p, nil
+		})
+
+	testCases := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expected       PatchableResource
+	}{
+		{"MergePatch", "application/merge-patch+json", `{"name":"Patched"}`, http.StatusOK,
+			PatchableResource{ID: "1", Name: "Patched", Tags: []string{"a", "b"}}},
+		{"MergePatchRemove", "application/merge-patch+json", `{"tags":null}`, http.StatusOK,
+			PatchableResource{ID: "1", Name: "Test"}},
+		{"JSONPatch", "application/json-patch+json",
+			`[{"op":"test","path":"/name","value":"Test"},{"op":"add","path":"/tags/1","value":"c"},{"op":"remove","path":"/tags/0"},{"op":"copy","from":"/name","path":"/tags/-"}]`,
+			http.StatusOK, PatchableResource{ID: "1", Name: "Test", Tags: []string{"c", "b", "Test"}}},
+		{"JSONPatchMove", "application/json-patch+json", `[{"op":"move","from":"/tags/1","path":"/name"}]`, http.StatusOK,
+			PatchableResource{ID: "1", Name: "b", Tags: []string{"a"}}},
+		{"JSONPatchTestFailed", "application/json-patch+json", `[{"op":"test","path":"/name","value":"Other"}]`, http.StatusConflict,
+			PatchableResource{}},
+		{"JSONPatchMissingMember", "application/json-patch+json", `[{"op":"replace","path":"/missing","value":1}]`, http.StatusUnprocessableEntity,
+			PatchableResource{}},
+		{"ValidationFailed", "application/merge-patch+json", `{"name":""}`, http.StatusBadRequest,
+			PatchableResource{}},
+		{"UnsupportedMediaType", "text/plain", `name`, http.StatusUnsupportedMediaType,
+			PatchableResource{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", "/mocks/1", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			rr := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var got PatchableResource
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("Could not decode response: %v", err)
+			}
+			gotRaw, _ := json.Marshal(got)
+			expectedRaw, _ := json.Marshal(tc.expected)
+			if string(gotRaw) != string(expectedRaw) {
+				t.Errorf("unexpected result: got %s want %s", gotRaw, expectedRaw)
+			}
+		})
+	}
+}
+
+type SecretResource struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Secret string `json:"-"`
+	Nested struct {
+		Note   string `json:"note"`
+		Hidden string `json:"-"`
+	} `json:"nested"`
+	version int
+}
+
+func (s SecretResource) ValidateCreate(ctx resource.Context) error            { return nil }
+func (s SecretResource) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func TestPatchKeepsHiddenFields(t *testing.T) {
+	current := SecretResource{ID: "1", Name: "Test", Secret: "hash", version: 3}
+	current.Nested.Note = "note"
+	current.Nested.Hidden = "hidden"
+
+	var patched SecretResource
+	handler := resource.New[SecretResource]().
+		Name("secret").
+		Plural("secrets").
+		Get(func(ctx resource.Context, id string) (SecretResource, error) { return current, nil }).
+		Patch(func(ctx resource.Context, id string, s SecretResource) (SecretResource, error) {
+			patched = s
+			return s, nil
+		}).
+		Handler()
+
+	req := httptest.NewRequest("PATCH", "/secrets/1", strings.NewReader(`{"name":"Patched","nested":{"note":null}}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	expected := SecretResource{ID: "1", Name: "Patched", Secret: "hash", version: 3}
+	expected.Nested.Hidden = "hidden"
+	if patched != expected {
+		t.Errorf("unexpected patched resource: got %+v want %+v", patched, expected)
+	}
+}
@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemDetails is an RFC 9457 error response, written instead of
+// ErrorResponse by resources with ProblemDetails enabled.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Errors lists the invalid fields of validation errors.
+	Errors []FieldError `json:"errors,omitempty"`
+
+	// Extensions are serialized as top level members.
+	Extensions map[string]any `json:"-" xml:"-"`
+}
+
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	type problem ProblemDetails
+	raw, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return raw, err
+	}
+	members := make(map[string]any, len(p.Extensions))
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	type problem ProblemDetails
+	if err := json.Unmarshal(data, (*problem)(p)); err != nil {
+		return err
+	}
+	var members map[string]any
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	for _, known := range []string{"type", "title", "status", "detail", "instance", "errors"} {
+		delete(members, known)
+	}
+	if len(members) > 0 {
+		p.Extensions = members
+	}
+	return nil
+}
+
+// ProblemDetails makes the resource report errors as application/problem+json.
+func (r *Resource[T]) ProblemDetails(enabled bool) *Resource[T] {
+	r.problemDetails = enabled
+	return r
+}
+
+// NewProblemDetails converts err the same way as the resource handlers do.
+// The type and extensions are taken from Error, when err wraps one.
+func NewProblemDetails(code int, err error, instance string) ProblemDetails {
+	code = statusFromError(err, code)
+	p := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(code),
+		Status:   code,
+		Detail:   err.Error(),
+		Instance: instance,
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		if e.Type != "" {
+			p.Type = e.Type
+		}
+		if e.Reason != "" || len(e.Extensions) > 0 {
+			p.Extensions = make(map[string]any, len(e.Extensions)+1)
+			for k, v := range e.Extensions {
+				p.Extensions[k] = v
+			}
+			if e.Reason != "" {
+				p.Extensions["reason"] = e.Reason
+			}
+		}
+	}
+	var fe FieldErrorer
+	if errors.As(err, &fe) {
+		p.Errors = fe.FieldErrors()
+	}
+	return p
+}
@@ -0,0 +1,113 @@
+package resource_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type invalidNameError struct{}
+
+func (invalidNameError) Error() string { return "invalid name" }
+func (invalidNameError) FieldErrors() []resource.FieldError {
+	return []resource.FieldError{{Pointer: "/name", Code: "required", Message: "must not be empty"}}
+}
+
+func TestProblemDetails(t *testing.T) {
+	custom := resource.NewError(http.StatusTooManyRequests, "SlowDown", "too fast")
+	custom.Type = "https://example.com/problems/rate-limit"
+	custom.Extensions = map[string]any{"retryAfter": float64(30)}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected resource.ProblemDetails
+	}{
+		{
+			name: "Sentinel",
+			err:  resource.ErrNotFound,
+			expected: resource.ProblemDetails{
+				Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "Not Found", Instance: "/mocks/1",
+				Extensions: map[string]any{"reason": "NotFound"},
+			},
+		},
+		{
+			name: "Custom",
+			err:  fmt.Errorf("lookup: %w", custom),
+			expected: resource.ProblemDetails{
+				Type: custom.Type, Title: "Too Many Requests", Status: http.StatusTooManyRequests, Detail: "lookup: too fast", Instance: "/mocks/1",
+				Extensions: map[string]any{"reason": "SlowDown", "retryAfter": float64(30)},
+			},
+		},
+		{
+			name: "FieldErrors",
+			err:  resource.ErrUnprocessable.Wrap(invalidNameError{}),
+			expected: resource.ProblemDetails{
+				Type: "about:blank", Title: "Unprocessable Entity", Status: http.StatusUnprocessableEntity, Detail: resource.ErrUnprocessable.Wrap(invalidNameError{}).Error(), Instance: "/mocks/1",
+				Errors:     []resource.FieldError{{Pointer: "/name", Code: "required", Message: "must not be empty"}},
+				Extensions: map[string]any{"reason": "Unprocessable"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := resource.New[MockResource]().
+				Name("mock").
+				Plural("mocks").
+				ProblemDetails(true).
+				Get(func(ctx resource.Context, id string) (MockResource, error) {
+					return MockResource{}, tc.err
+				})
+
+			req := httptest.NewRequest("GET", "/mocks/1", nil)
+			rr := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expected.Status {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					rr.Code, tc.expected.Status)
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != resource.MIMEApplicationProblemJSON {
+				t.Errorf("handler returned wrong content type: got %v want %v",
+					ct, resource.MIMEApplicationProblemJSON)
+			}
+
+			var got resource.ProblemDetails
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("Could not decode problem details: %v", err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			expectedJSON, _ := json.Marshal(tc.expected)
+			if string(gotJSON) != string(expectedJSON) {
+				t.Errorf("Unexpected problem details: got %s want %s", gotJSON, expectedJSON)
+			}
+		})
+	}
+}
+
+func TestErrorResponseDetail(t *testing.T) {
+	r := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		Get(func(ctx resource.Context, id string) (MockResource, error) {
+			return MockResource{}, resource.ErrInternal.Wrap(errors.New("connection refused"))
+		})
+
+	req := httptest.NewRequest("GET", "/mocks/1", nil)
+	rr := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rr, req)
+
+	var errResp resource.ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+		t.Fatalf("Could not decode error response: %v", err)
+	}
+	if errResp.Error != "connection refused" {
+		t.Errorf("Unexpected error detail: got %#v", errResp.Error)
+	}
+}
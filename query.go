@@ -0,0 +1,302 @@
+package resource
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ListQuery is the parsed query of a list request, e.g.
+//
+//	?filter=status eq "active"&sort=-createdAt,name&fields=id,name&limit=10
+type ListQuery struct {
+	Offset  int
+	Limit   int
+	Filters []Filter
+	Sort    []SortKey
+	Fields  []string
+
+	// Cursor is the verified cursor of a CursorList request, empty for the first page.
+	Cursor string
+}
+
+type FilterOperator string
+
+const (
+	FilterEqual          FilterOperator = "eq"
+	FilterNotEqual       FilterOperator = "ne"
+	FilterGreater        FilterOperator = "gt"
+	FilterGreaterOrEqual FilterOperator = "ge"
+	FilterLess           FilterOperator = "lt"
+	FilterLessOrEqual    FilterOperator = "le"
+	FilterContains       FilterOperator = "contains"
+)
+
+var filterOperators = []FilterOperator{
+	FilterEqual, FilterNotEqual, FilterGreater, FilterGreaterOrEqual, FilterLess, FilterLessOrEqual, FilterContains,
+}
+
+// Filter is a single condition. Value is always the unquoted string form,
+// so it is up to the callback to interpret it.
+type Filter struct {
+	Field    string
+	Operator FilterOperator
+	Value    string
+}
+
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// FiltersOn returns every filter on field.
+func (q ListQuery) FiltersOn(field string) []Filter {
+	var filters []Filter
+	for _, f := range q.Filters {
+		if f.Field == field {
+			filters = append(filters, f)
+		}
+	}
+	return filters
+}
+
+// query parameters with a meaning of their own, never treated as filters
+var reservedParams = []string{"limit", "offset", "cursor", "filter", "sort", "fields"}
+
+func (r *Resource[T]) parseListQuery(req *http.Request) (ListQuery, error) {
+	var q ListQuery
+	var err error
+	if q.Limit, err = parseParamsInt(req, "limit", r.defaultLimits); err != nil {
+		return q, err
+	}
+	switch {
+	case q.Limit < 1:
+		return q, fmt.Errorf("limit must be at least 1, got %d", q.Limit)
+	case r.maxLimit > 0 && q.Limit > r.maxLimit:
+		return q, fmt.Errorf("limit must be at most %d, got %d", r.maxLimit, q.Limit)
+	}
+	if q.Offset, err = parseParamsInt(req, "offset", 0); err != nil {
+		return q, err
+	}
+	if q.Offset < 0 {
+		return q, fmt.Errorf("offset must not be negative, got %d", q.Offset)
+	}
+
+	values := req.URL.Query()
+	for _, raw := range values["filter"] {
+		filters, err := parseFilter(raw)
+		if err != nil {
+			return q, err
+		}
+		q.Filters = append(q.Filters, filters...)
+	}
+	// shorthand for equality, e.g. ?status=active
+	for _, field := range r.filterable {
+		if slices.Contains(reservedParams, field) {
+			continue
+		}
+		for _, v := range values[field] {
+			q.Filters = append(q.Filters, Filter{Field: field, Operator: FilterEqual, Value: v})
+		}
+	}
+	for _, f := range q.Filters {
+		if !slices.Contains(r.filterable, f.Field) {
+			return q, fmt.Errorf("field %q is not filterable", f.Field)
+		}
+	}
+
+	if q.Sort, err = parseSort(values.Get("sort")); err != nil {
+		return q, err
+	}
+	for _, s := range q.Sort {
+		if !slices.Contains(r.sortable, s.Field) {
+			return q, fmt.Errorf("field %q is not sortable", s.Field)
+		}
+	}
+
+	if q.Fields, err = r.parseFields(req); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+func (r *Resource[T]) parseFields(req *http.Request) ([]string, error) {
+	raw := req.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+	var fields []string
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if !slices.Contains(r.selectable, field) {
+			return nil, fmt.Errorf("field %q is not selectable", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func parseSort(raw string) ([]SortKey, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var keys []SortKey
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		key := SortKey{Field: strings.TrimLeft(field, "+-"), Desc: strings.HasPrefix(field, "-")}
+		if key.Field == "" {
+			return nil, fmt.Errorf("invalid sort parameter %q", raw)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// parseFilter parses conditions such as `status eq "active" and age gt 20`.
+func parseFilter(raw string) ([]Filter, error) {
+	tokens, err := tokenizeFilter(raw)
+	if err != nil {
+		return nil, err
+	}
+	var filters []Filter
+	for len(tokens) > 0 {
+		if len(tokens) < 3 {
+			return nil, fmt.Errorf("invalid filter %q: expected <field> <operator> <value>", raw)
+		}
+		op := FilterOperator(strings.ToLower(tokens[1]))
+		if !slices.Contains(filterOperators, op) {
+			return nil, fmt.Errorf("invalid filter %q: unknown operator %q", raw, tokens[1])
+		}
+		filters = append(filters, Filter{Field: tokens[0], Operator: op, Value: tokens[2]})
+		tokens = tokens[3:]
+
+		if len(tokens) > 0 {
+			if !strings.EqualFold(tokens[0], "and") {
+				return nil, fmt.Errorf("invalid filter %q: expected \"and\" but got %q", raw, tokens[0])
+			}
+			tokens = tokens[1:]
+			if len(tokens) == 0 {
+				return nil, fmt.Errorf("invalid filter %q: dangling \"and\"", raw)
+			}
+		}
+	}
+	return filters, nil
+}
+
+func tokenizeFilter(raw string) ([]string, error) {
+	var tokens []string
+	s := strings.TrimSpace(raw)
+	for s != "" {
+		if s[0] == '"' {
+			// find the closing quote, skipping escaped ones
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("invalid filter %q: unterminated string", raw)
+			}
+			var token string
+			if err := json.Unmarshal([]byte(s[:end+1]), &token); err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", raw, err)
+			}
+			tokens = append(tokens, token)
+			s = s[end+1:]
+		} else {
+			end := strings.IndexFunc(s, unicode.IsSpace)
+			if end < 0 {
+				end = len(s)
+			}
+			tokens = append(tokens, s[:end])
+			s = s[end:]
+		}
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	}
+	return tokens, nil
+}
+
+// selectFields keeps only the given JSON members of v.
+func selectFields(v any, fields []string) (selection, error) {
+	all, err := jsonDocument(v)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(selection, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = plainNumbers(value)
+		}
+	}
+	return selected, nil
+}
+
+// selection holds the members picked with ?fields= as plain values rather
+// than JSON text, so that every codec can encode them.
+type selection map[string]any
+
+// MarshalXML encodes the members as child elements in name order, since
+// encoding/xml does not support maps.
+func (s selection) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return encodeXMLValue(e, start, map[string]any(s))
+}
+
+func encodeXMLValue(e *xml.Encoder, start xml.StartElement, v any) error {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := encodeXMLValue(e, xml.StartElement{Name: xml.Name{Local: key}}, v[key]); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(start.End())
+	case []any:
+		// like encoding/xml, elements of arrays repeat the element of the array
+		for _, elem := range v {
+			if err := encodeXMLValue(e, start, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return e.EncodeElement(v, start)
+}
+
+// plainNumbers replaces the json.Number of decoded JSON values with int64,
+// or float64 when they are not integers.
+func plainNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, value := range v {
+			v[key] = plainNumbers(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = plainNumbers(value)
+		}
+	}
+	return v
+}
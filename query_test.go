@@ -0,0 +1,87 @@
+package resource_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+func TestListQuery(t *testing.T) {
+	var got resource.ListQuery
+	r := resource.New[MockResource]().
+		Name("mock").
+		Plural("mocks").
+		Filterable("name", "status").
+		Sortable("name", "createdAt").
+		Selectable("id", "name").
+		List(func(ctx resource.Context, query resource.ListQuery) ([]MockResource, error) {
+			got = query
+			return []MockResource{{ID: "1", Name: "Test"}}, nil
+		})
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expected       resource.ListQuery
+		expectedBody   string
+	}{
+		{"Default", "", http.StatusOK,
+			resource.ListQuery{Limit: 10}, `{"items":[{"id":"1","name":"Test"}],"metadata":{"offset":0,"limit":10}}`},
+		{"Filter", `filter=name eq "a \"quoted\" b" and status ne closed`, http.StatusOK,
+			resource.ListQuery{Limit: 10, Filters: []resource.Filter{
+				{Field: "name", Operator: resource.FilterEqual, Value: `a "quoted" b`},
+				{Field: "status", Operator: resource.FilterNotEqual, Value: "closed"},
+			}}, ""},
+		{"FilterShorthand", "status=active&limit=5&offset=5", http.StatusOK,
+			resource.ListQuery{Offset: 5, Limit: 5, Filters: []resource.Filter{
+				{Field: "status", Operator: resource.FilterEqual, Value: "active"},
+			}}, ""},
+		{"Sort", "sort=-createdAt,name", http.StatusOK,
+			resource.ListQuery{Limit: 10, Sort: []resource.SortKey{
+				{Field: "createdAt", Desc: true},
+				{Field: "name"},
+			}}, ""},
+		{"Fields", "fields=name", http.StatusOK,
+			resource.ListQuery{Limit: 10, Fields: []string{"name"}}, `{"items":[{"name":"Test"}],"metadata":{"offset":0,"limit":10}}`},
+		{"NotFilterable", `filter=id eq 1`, http.StatusBadRequest, resource.ListQuery{}, ""},
+		{"UnknownOperator", `filter=name like 1`, http.StatusBadRequest, resource.ListQuery{}, ""},
+		{"MalformedFilter", `filter=name eq`, http.StatusBadRequest, resource.ListQuery{}, ""},
+		{"NotSortable", "sort=id", http.StatusBadRequest, resource.ListQuery{}, ""},
+		{"NotSelectable", "fields=secret", http.StatusBadRequest, resource.ListQuery{}, ""},
+		{"ZeroLimit", "limit=0", http.StatusBadRequest, resource.ListQuery{}, ""},
+		{"NegativeLimit", "limit=-1", http.StatusBadRequest, resource.ListQuery{}, ""},
+		{"LimitAboveMax", "limit=1001", http.StatusBadRequest, resource.ListQuery{}, ""},
+		{"MaxLimit", "limit=1000", http.StatusOK, resource.ListQuery{Limit: 1000}, ""},
+		{"NegativeOffset", "offset=-1", http.StatusBadRequest, resource.ListQuery{}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = resource.ListQuery{}
+			values, _ := url.ParseQuery(tc.query)
+			req := httptest.NewRequest("GET", "/mocks?"+values.Encode(), nil)
+			rr := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("unexpected query: got %+v want %+v", got, tc.expected)
+			}
+			if tc.expectedBody != "" && strings.TrimSpace(rr.Body.String()) != tc.expectedBody {
+				t.Errorf("unexpected body: got %s want %s", rr.Body.String(), tc.expectedBody)
+			}
+		})
+	}
+}
@@ -2,14 +2,27 @@ package resource
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"sort"
 )
 
 const (
-	MIMEApplicationJSON = "application/json"
-	HeaderContentType   = "Content-Type"
+	MIMEApplicationJSON           = "application/json"
+	MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+	MIMEApplicationJSONPatchJSON  = "application/json-patch+json"
+	MIMEApplicationProblemJSON    = "application/problem+json"
+	MIMEApplicationProblemXML     = "application/problem+xml"
+	HeaderContentType             = "Content-Type"
+	HeaderAccept                  = "Accept"
+	HeaderLink                    = "Link"
+	HeaderETag                    = "ETag"
+	HeaderIfMatch                 = "If-Match"
+	HeaderIfNoneMatch             = "If-None-Match"
+	HeaderRequestID               = "X-Request-ID"
+	HeaderWWWAuthenticate         = "WWW-Authenticate"
 )
 
 type Validator interface {
@@ -25,11 +38,20 @@ type ResourceList[T Validator] struct {
 type Metadata struct {
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
+
+	// set when the resource has a Count callback, or the answer is known otherwise
+	Total   *int  `json:"total,omitempty"`
+	HasMore *bool `json:"hasMore,omitempty"`
+
+	// set by resources listed with CursorList
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
 }
 
 type ErrorResponse struct {
 	Message string      `json:"message"`
 	Code    int         `json:"code"`
+	Reason  string      `json:"reason,omitempty"`
 	Error   interface{} `json:"error"`
 }
 
@@ -37,10 +59,13 @@ type Context struct {
 	context.Context
 }
 
-type List[T Validator] func(ctx Context, offset int, limit int) ([]T, error)
+type List[T Validator] func(ctx Context, query ListQuery) ([]T, error)
+type CursorList[T Validator] func(ctx Context, query ListQuery) (Page[T], error)
+type Count[T Validator] func(ctx Context, query ListQuery) (int, error)
 type Create[T Validator] func(ctx Context, resource T) (T, error)
 type Update[T Validator] func(ctx Context, id string, resource T) (T, error)
 type Get[T Validator] func(ctx Context, id string) (T, error)
+type Patch[T Validator] func(ctx Context, id string, resource T) (T, error)
 type Delete[T Validator] func(ctx Context, id string) error
 type SubresourceHandler[T Validator] func(ctx Context, w http.ResponseWriter, r *http.Request)
 
@@ -51,22 +76,75 @@ type Resource[T Validator] struct {
 	// base path for the resource endpoints e.g. /api/v1/
 	base string
 
-	list   List[T]
-	create Create[T]
-	get    Get[T]
-	update Update[T]
-	delete Delete[T]
+	list       List[T]
+	cursorList CursorList[T]
+	count      Count[T]
+	create     Create[T]
+	get        Get[T]
+	update     Update[T]
+	patch      Patch[T]
+	delete     Delete[T]
+
+	batchCreate    BatchCreate[T]
+	batchUpdate    BatchUpdate[T]
+	batchDelete    BatchDelete[T]
+	partialBatches bool
+	maxBatchSize   int
 
 	subresources map[string]SubresourceHandler[T]
 
-	// default limits for list requests
+	// nested resources, and the resource this one is nested in
+	children []Registrar
+	parent   parent
+
+	// group the resource was added to, whose settings it inherits
+	group *Group
+	// misplaced records the groups and parents the resource was added to
+	// after the first one
+	misplaced []error
+
+	middlewares          []Middleware
+	afterAuthMiddlewares []Middleware
+	operationMiddlewares map[Operation][]Middleware
+
+	authenticators []Authenticator
+	authorizer     Authorizer
+
+	logger *slog.Logger
+
+	// codecs of request and response bodies, the first one being the default
+	codecs []Codec
+
+	// report errors as RFC 9457 problem details instead of ErrorResponse
+	problemDetails bool
+
+	// limits and strictness of request bodies
+	maxBodySize        int64
+	strictJSON         bool
+	requireContentType bool
+
+	// key used to sign cursors of CursorList
+	cursorSecret []byte
+
+	// default and maximum limits for list requests
 	defaultLimits int
+	maxLimit      int
+
+	// fields allowed in the filter, sort and fields parameters of list requests
+	filterable []string
+	sortable   []string
+	selectable []string
 }
 
 func New[T Validator]() *Resource[T] {
 	return &Resource[T]{
-		subresources:  make(map[string]SubresourceHandler[T]),
-		defaultLimits: 10,
+		subresources:         make(map[string]SubresourceHandler[T]),
+		operationMiddlewares: make(map[Operation][]Middleware),
+		defaultLimits:        10,
+		maxLimit:             1000,
+		maxBatchSize:         1000,
+		cursorSecret:         randomSecret(),
+		codecs:               []Codec{JSONCodec},
 	}
 }
 
@@ -80,11 +158,49 @@ func (r *Resource[T]) Plural(plural string) *Resource[T] {
 	return r
 }
 
+// Base sets the path prefix of the resource endpoints, e.g. /api/v1
+func (r *Resource[T]) Base(base string) *Resource[T] {
+	r.base = cleanBase(base)
+	return r
+}
+
 func (r *Resource[T]) List(f List[T]) *Resource[T] {
 	r.list = f
 	return r
 }
 
+// Filterable declares the fields accepted by ?filter= and the ?<field>= shorthand.
+func (r *Resource[T]) Filterable(fields ...string) *Resource[T] {
+	r.filterable = append(r.filterable, fields...)
+	return r
+}
+
+// Sortable declares the fields accepted by ?sort=.
+func (r *Resource[T]) Sortable(fields ...string) *Resource[T] {
+	r.sortable = append(r.sortable, fields...)
+	return r
+}
+
+// Selectable declares the fields accepted by ?fields=.
+func (r *Resource[T]) Selectable(fields ...string) *Resource[T] {
+	r.selectable = append(r.selectable, fields...)
+	return r
+}
+
+// MaxLimit caps the ?limit= of list requests, larger limits being rejected
+// with 400. Defaults to 1000, and zero removes the cap.
+func (r *Resource[T]) MaxLimit(n int) *Resource[T] {
+	r.maxLimit = n
+	return r
+}
+
+// Count enables the total and hasMore list metadata, and the last link.
+// It receives the same query as the list callback.
+func (r *Resource[T]) Count(f Count[T]) *Resource[T] {
+	r.count = f
+	return r
+}
+
 func (r *Resource[T]) Create(f Create[T]) *Resource[T] {
 	r.create = f
 	return r
@@ -100,41 +216,115 @@ func (r *Resource[T]) Update(f Update[T]) *Resource[T] {
 	return r
 }
 
+// Patch enables PATCH requests. The patch is applied to the object loaded
+// with Get, so Get must be set as well.
+func (r *Resource[T]) Patch(f Patch[T]) *Resource[T] {
+	r.patch = f
+	return r
+}
+
 func (r *Resource[T]) Delete(f Delete[T]) *Resource[T] {
 	r.delete = f
 	return r
 }
 
+// RegisterMux registers the routes of the resource and of its nested
+// resources. It panics with the error of Validate, before registering any route.
 func (b *Resource[T]) RegisterMux(mux *http.ServeMux) *Resource[T] {
-	if b.list != nil {
-		pattern := fmt.Sprintf("GET %s/%s", b.base, b.plural)
-		mux.HandleFunc(pattern, b.handlerList)
+	mustValidate(b.Validate())
+	b.register(mux)
+	return b
+}
+
+func (b *Resource[T]) register(mux *http.ServeMux) {
+	for _, route := range b.routes() {
+		pattern := route.Path
+		if route.Method != "" {
+			pattern = fmt.Sprintf("%s %s", route.Method, route.Path)
+		}
+		mux.Handle(pattern, b.wrap(route))
+	}
+	for _, child := range b.children {
+		child.register(mux)
+	}
+}
+
+func (b *Resource[T]) wrap(route route) http.Handler {
+	middlewares := []Middleware{b.contextMiddleware(route)}
+	if route.Operation != OperationSubresource {
+		middlewares = append(middlewares, b.negotiationMiddleware)
+		if b.maxBodySize > 0 {
+			middlewares = append(middlewares, b.bodyLimitMiddleware)
+		}
+	}
+	if g := b.inGroup(); g != nil {
+		middlewares = append(middlewares, g.middlewares...)
+	}
+	middlewares = append(middlewares, b.middlewares...)
+	if len(b.allAuthenticators()) > 0 || b.authorizes() {
+		middlewares = append(middlewares, b.authMiddleware(route))
+	}
+	if b.parent != nil {
+		middlewares = append(middlewares, b.parentMiddleware)
+	}
+	middlewares = append(middlewares, b.afterAuthMiddlewares...)
+	middlewares = append(middlewares, b.operationMiddlewares[route.Operation]...)
+	return chain(route.handler, middlewares...)
+}
+
+type route struct {
+	Route
+	handler http.HandlerFunc
+}
+
+func (b *Resource[T]) routes() []route {
+	collection := b.collectionPath()
+	item := b.itemPath()
+
+	var routes []route
+	if b.cursorList != nil {
+		routes = append(routes, route{Route{http.MethodGet, collection, OperationList, ""}, b.handlerCursorList})
+	} else if b.list != nil {
+		routes = append(routes, route{Route{http.MethodGet, collection, OperationList, ""}, b.handlerList})
 	}
 	if b.create != nil {
-		pattern := fmt.Sprintf("POST %s/%s", b.base, b.plural)
-		mux.HandleFunc(pattern, b.handlerCreate)
+		routes = append(routes, route{Route{http.MethodPost, collection, OperationCreate, ""}, b.handlerCreate})
 	}
 	if b.get != nil {
-		pattern := fmt.Sprintf("GET %s/%s/{%s}", b.base, b.plural, b.pathID())
-		mux.HandleFunc(pattern, b.handlerGet)
+		routes = append(routes, route{Route{http.MethodGet, item, OperationGet, ""}, b.handlerGet})
 	}
 	if b.update != nil {
-		pattern := fmt.Sprintf("PUT %s/%s/{%s}", b.base, b.plural, b.pathID())
-		mux.HandleFunc(pattern, b.handlerUpdate)
+		routes = append(routes, route{Route{http.MethodPut, item, OperationUpdate, ""}, b.handlerUpdate})
+	}
+	if b.patch != nil && b.get != nil {
+		routes = append(routes, route{Route{http.MethodPatch, item, OperationPatch, ""}, b.handlerPatch})
 	}
 	if b.delete != nil {
-		pattern := fmt.Sprintf("DELETE %s/%s/{%s}", b.base, b.plural, b.pathID())
-		mux.HandleFunc(pattern, b.handlerDelete)
+		routes = append(routes, route{Route{http.MethodDelete, item, OperationDelete, ""}, b.handlerDelete})
+	}
+	if b.batchCreate != nil {
+		routes = append(routes, route{Route{http.MethodPost, collection + ":batchCreate", OperationBatchCreate, ""}, b.handlerBatchCreate})
+	}
+	if b.batchUpdate != nil {
+		routes = append(routes, route{Route{http.MethodPost, collection + ":batchUpdate", OperationBatchUpdate, ""}, b.handlerBatchUpdate})
+	}
+	if b.batchDelete != nil {
+		routes = append(routes, route{Route{http.MethodPost, collection + ":batchDelete", OperationBatchDelete, ""}, b.handlerBatchDelete})
 	}
-	for name, handler := range b.subresources {
-		pattern := fmt.Sprintf("%s/%s/{%s}/%s/", b.base, b.plural, b.pathID(), name)
 
-		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(b.subresources))
+	for name := range b.subresources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		handler := b.subresources[name]
+		path := fmt.Sprintf("%s/%s/", item, name)
+		routes = append(routes, route{Route{"", path, OperationSubresource, name}, func(w http.ResponseWriter, r *http.Request) {
 			handler(newContext(r), w, r)
-		})
+		}})
 	}
-
-	return b
+	return routes
 }
 
 func (b *Resource[T]) RegisterSubresource(name string, handler SubresourceHandler[T]) *Resource[T] {
@@ -150,28 +340,60 @@ func (b *Resource[T]) Handler() http.Handler {
 
 func (b *Resource[T]) handlerList(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r)
-	limit, err := parseParamsInt(r, "limit", b.defaultLimits)
+	query, err := b.parseListQuery(r)
 	if err != nil {
-		JSONError(w, http.StatusBadRequest, err)
+		WriteError(w, http.StatusBadRequest, err)
 		return
 	}
-	offset, err := parseParamsInt(r, "offset", 0)
+	result, err := b.list(ctx, query)
 	if err != nil {
-		JSONError(w, http.StatusBadRequest, err)
+		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	result, err := b.list(ctx, offset, limit)
-	if err != nil {
-		JSONError(w, http.StatusInternalServerError, err)
+	metadata := Metadata{
+		Offset: query.Offset,
+		Limit:  query.Limit,
+	}
+	if b.count != nil {
+		total, err := b.count(ctx, query)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		hasMore := query.Offset+len(result) < total
+		metadata.Total = &total
+		metadata.HasMore = &hasMore
+	}
+	setOffsetLinks(w, r, metadata, len(result))
+	writeList(w, result, query.Fields, metadata)
+}
+
+func writeList[T Validator](w http.ResponseWriter, result []T, fields []string, metadata Metadata) {
+	if result == nil {
+		// encode an empty page as [] rather than null
+		result = []T{}
+	}
+	if len(fields) == 0 {
+		Write(w, http.StatusOK, ResourceList[T]{
+			Items:    result,
+			Metadata: metadata,
+		})
 		return
 	}
-	JSON(w, http.StatusOK, ResourceList[T]{
-		Items: result,
-		Metadata: Metadata{
-			Offset: offset,
-			Limit:  limit,
-		},
-	})
+
+	items := make([]selection, 0, len(result))
+	for _, item := range result {
+		selected, err := selectFields(item, fields)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		items = append(items, selected)
+	}
+	Write(w, http.StatusOK, struct {
+		Items    []selection `json:"items"`
+		Metadata `json:"metadata"`
+	}{items, metadata})
 }
 
 func (b *Resource[T]) pathID() string {
@@ -181,71 +403,143 @@ func (b *Resource[T]) pathID() string {
 func (b *Resource[T]) handlerCreate(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r)
 	body := make([]T, 1)[0]
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		JSONError(w, http.StatusBadRequest, err)
+	if err := b.decode(r, &body); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
 		return
 	}
-	if err := body.ValidateCreate(ctx); err != nil {
-		JSONError(w, http.StatusBadRequest, err)
+	if err := validateCreate(ctx, body); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 	result, err := b.create(ctx, body)
 	if err != nil {
-		JSONError(w, http.StatusInternalServerError, err)
+		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	JSON(w, http.StatusCreated, result)
+	setETag(w, result)
+	Write(w, http.StatusCreated, result)
 }
 
 func (b *Resource[T]) handlerGet(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r)
 	id := r.PathValue(b.pathID())
 	if id == "" {
-		JSONError(w, http.StatusBadRequest, fmt.Errorf("missing resource-id"))
+		WriteError(w, http.StatusBadRequest, fmt.Errorf("missing resource-id"))
+		return
+	}
+	fields, err := b.parseFields(r)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 	result, err := b.get(ctx, id)
 	if err != nil {
-		JSONError(w, http.StatusInternalServerError, err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setETag(w, result)
+	if notModified(r, etagOf(result)) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if len(fields) > 0 {
+		selected, err := selectFields(result, fields)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, err)
+			return
+		}
+		Write(w, http.StatusOK, selected)
 		return
 	}
-	JSON(w, http.StatusOK, result)
+	Write(w, http.StatusOK, result)
 }
 
 func (b *Resource[T]) handlerUpdate(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r)
 	id := r.PathValue(b.pathID())
 	if id == "" {
-		JSONError(w, http.StatusBadRequest, fmt.Errorf("missing resource-id"))
+		WriteError(w, http.StatusBadRequest, fmt.Errorf("missing resource-id"))
 		return
 	}
 	body := make([]T, 1)[0]
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		JSONError(w, http.StatusBadRequest, err)
+	if err := b.decode(r, &body); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
 		return
 	}
-	if err := body.ValidateUpdate(ctx, id); err != nil {
-		JSONError(w, http.StatusBadRequest, err)
+	if err := validateUpdate(ctx, id, body); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := b.checkIfMatch(ctx, r, id); err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 	result, err := b.update(ctx, id, body)
 	if err != nil {
-		JSONError(w, http.StatusInternalServerError, err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setETag(w, result)
+	Write(w, http.StatusOK, result)
+}
+
+func (b *Resource[T]) handlerPatch(w http.ResponseWriter, r *http.Request) {
+	ctx := newContext(r)
+	id := r.PathValue(b.pathID())
+	if id == "" {
+		WriteError(w, http.StatusBadRequest, fmt.Errorf("missing resource-id"))
+		return
+	}
+	if err := b.checkContentType(r); err != nil {
+		WriteError(w, http.StatusUnsupportedMediaType, err)
+		return
+	}
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, bodyError(err))
 		return
 	}
-	JSON(w, http.StatusOK, result)
+	current, err := b.get(ctx, id)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := ifMatch(r, current); err != nil {
+		WriteError(w, http.StatusPreconditionFailed, err)
+		return
+	}
+	body := patchTarget(current)
+	if err := applyPatch(r.Header.Get(HeaderContentType), current, patch, &body, b.strictJSON); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := validateUpdate(ctx, id, body); err != nil {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	result, err := b.patch(ctx, id, body)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setETag(w, result)
+	Write(w, http.StatusOK, result)
 }
 
 func (b *Resource[T]) handlerDelete(w http.ResponseWriter, r *http.Request) {
 	ctx := newContext(r)
 	id := r.PathValue(b.pathID())
 	if id == "" {
-		JSONError(w, http.StatusBadRequest, fmt.Errorf("missing resource-id"))
+		WriteError(w, http.StatusBadRequest, fmt.Errorf("missing resource-id"))
+		return
+	}
+	if err := b.checkIfMatch(ctx, r, id); err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 	if err := b.delete(ctx, id); err != nil {
-		JSONError(w, http.StatusInternalServerError, err)
+		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	JSON(w, http.StatusNoContent, nil)
+	Write(w, http.StatusNoContent, nil)
 }
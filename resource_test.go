@@ -29,7 +29,7 @@ func (m MockResource) ValidateUpdate(ctx resource.Context, id string) error {
 }
 
 // Mock functions for CRUD operations
-func mockList(ctx resource.Context, offset int, limit int) ([]MockResource, error) {
+func mockList(ctx resource.Context, query resource.ListQuery) ([]MockResource, error) {
 	return []MockResource{{ID: "1", Name: "Test"}}, nil
 }
 
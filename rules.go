@@ -0,0 +1,307 @@
+package resource
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Rules are the constraints declared by the validate tag of a struct field,
+// e.g.
+//
+//	Name  string   `json:"name" validate:"required,maxLength=64"`
+//	Email string   `json:"email" validate:"email"`
+//	Role  string   `json:"role" validate:"enum=admin|member"`
+//	Age   int      `json:"age" validate:"min=0,max=150"`
+//	Tags  []string `json:"tags" validate:"maxItems=10"`
+//	Code  string   `json:"code" validate:"pattern=^[A-Z]{3}$"`
+//
+// min and max bound the value of numbers, the length of strings and the
+// number of items of slices. pattern takes the rest of the tag, so it must
+// be the last rule. Rules other than required are skipped for nil pointers,
+// and for zero values of omitempty fields, which are absent from the JSON
+// document. Zero values of other fields are checked like any value.
+type Rules struct {
+	Required  bool
+	Minimum   *float64
+	Maximum   *float64
+	MinLength *int
+	MaxLength *int
+	MinItems  *int
+	MaxItems  *int
+	Pattern   string
+	Enum      []string
+	Format    string // only "email" is checked
+}
+
+// FieldRules parses the validate tag of field.
+func FieldRules(field reflect.StructField) (Rules, error) {
+	var rules Rules
+	tag := field.Tag.Get("validate")
+	kind := field.Type.Kind()
+	if kind == reflect.Pointer {
+		kind = field.Type.Elem().Kind()
+	}
+
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "pattern=") {
+			rule, tag = tag, ""
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+		name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		var err error
+		switch name {
+		case "required":
+			rules.Required = true
+		case "email":
+			rules.Format = "email"
+		case "enum":
+			rules.Enum = strings.Split(value, "|")
+		case "pattern":
+			_, err = regexp.Compile(value)
+			rules.Pattern = value
+		case "minimum":
+			rules.Minimum, err = parseRuleFloat(value)
+		case "maximum":
+			rules.Maximum, err = parseRuleFloat(value)
+		case "minLength":
+			rules.MinLength, err = parseRuleInt(value)
+		case "maxLength":
+			rules.MaxLength, err = parseRuleInt(value)
+		case "minItems":
+			rules.MinItems, err = parseRuleInt(value)
+		case "maxItems":
+			rules.MaxItems, err = parseRuleInt(value)
+		case "min", "max":
+			switch kind {
+			case reflect.String:
+				if name == "min" {
+					rules.MinLength, err = parseRuleInt(value)
+				} else {
+					rules.MaxLength, err = parseRuleInt(value)
+				}
+			case reflect.Slice, reflect.Array, reflect.Map:
+				if name == "min" {
+					rules.MinItems, err = parseRuleInt(value)
+				} else {
+					rules.MaxItems, err = parseRuleInt(value)
+				}
+			default:
+				if name == "min" {
+					rules.Minimum, err = parseRuleFloat(value)
+				} else {
+					rules.Maximum, err = parseRuleFloat(value)
+				}
+			}
+		case "":
+		default:
+			err = fmt.Errorf("unknown rule")
+		}
+		if err != nil {
+			return rules, fmt.Errorf("invalid validate tag of field %s, rule %q: %w", field.Name, rule, err)
+		}
+	}
+	return rules, nil
+}
+
+func parseRuleInt(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	return &n, err
+}
+
+func parseRuleFloat(value string) (*float64, error) {
+	n, err := strconv.ParseFloat(value, 64)
+	return &n, err
+}
+
+// ValidateStruct checks v against the validate tags of its fields, including
+// those of nested structs. The failures are reported as a ValidationError,
+// with JSON pointers built from the json names of the fields.
+func ValidateStruct(v any) error {
+	var errs ValidationError
+	if err := validateValue(&errs, "", reflect.ValueOf(v)); err != nil {
+		return ErrInternal.Wrap(err)
+	}
+	return errs.Err()
+}
+
+// validateCreate runs the tag rules before the ValidateCreate method of body.
+func validateCreate[T Validator](ctx Context, body T) error {
+	if err := ValidateStruct(body); err != nil {
+		return err
+	}
+	return body.ValidateCreate(ctx)
+}
+
+// validateUpdate runs the tag rules before the ValidateUpdate method of body.
+func validateUpdate[T Validator](ctx Context, id string, body T) error {
+	if err := ValidateStruct(body); err != nil {
+		return err
+	}
+	return body.ValidateUpdate(ctx, id)
+}
+
+type fieldRules struct {
+	index     int
+	name      string // json name, empty for embedded structs
+	omitEmpty bool
+	rules     Rules
+	pattern   *regexp.Regexp
+}
+
+var rulesCache sync.Map // reflect.Type → []fieldRules
+
+func structRules(t reflect.Type) ([]fieldRules, error) {
+	if cached, ok := rulesCache.Load(t); ok {
+		return cached.([]fieldRules), nil
+	}
+	var fields []fieldRules
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		tag, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" && options == "" {
+			continue
+		} else if tag != "" {
+			name = tag
+		} else if field.Anonymous {
+			name = ""
+		}
+		rules, err := FieldRules(field)
+		if err != nil {
+			return nil, err
+		}
+		f := fieldRules{index: i, name: name, rules: rules, omitEmpty: slices.Contains(strings.Split(options, ","), "omitempty")}
+		if rules.Pattern != "" {
+			f.pattern = regexp.MustCompile(rules.Pattern)
+		}
+		fields = append(fields, f)
+	}
+	rulesCache.Store(t, fields)
+	return fields, nil
+}
+
+// checkTags parses the validate tags of t and of the structs it contains,
+// so that malformed tags are reported before any request is served.
+func checkTags(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+	fields, err := structRules(t)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err := checkTags(t.Field(f.index).Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateValue records the failures of v in errs. The returned error is
+// only set for malformed tags.
+func validateValue(errs *ValidationError, pointer string, v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		fields, err := structRules(v.Type())
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			fv := v.Field(f.index)
+			p := pointer
+			if f.name != "" {
+				p = pointer + Pointer(f.name)
+			}
+			checkRules(errs, p, f, fv)
+			if err := validateValue(errs, p, fv); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValue(errs, pointer+Pointer(i), v.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkRules(errs *ValidationError, pointer string, f fieldRules, v reflect.Value) {
+	rules := f.rules
+	if v.IsZero() {
+		if rules.Required {
+			errs.Add(pointer, "required", "is required")
+			return
+		}
+		if f.omitEmpty || v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			return
+		}
+	}
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		n := len([]rune(s))
+		if rules.MinLength != nil && n < *rules.MinLength {
+			errs.Addf(pointer, "minLength", "must be at least %d characters", *rules.MinLength)
+		}
+		if rules.MaxLength != nil && n > *rules.MaxLength {
+			errs.Addf(pointer, "maxLength", "must be at most %d characters", *rules.MaxLength)
+		}
+		if f.pattern != nil && !f.pattern.MatchString(s) {
+			errs.Addf(pointer, "pattern", "must match %s", rules.Pattern)
+		}
+		if rules.Format == "email" {
+			if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+				errs.Add(pointer, "email", "must be an email address")
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if rules.MinItems != nil && v.Len() < *rules.MinItems {
+			errs.Addf(pointer, "minItems", "must have at least %d items", *rules.MinItems)
+		}
+		if rules.MaxItems != nil && v.Len() > *rules.MaxItems {
+			errs.Addf(pointer, "maxItems", "must have at most %d items", *rules.MaxItems)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		n, _ := strconv.ParseFloat(fmt.Sprint(v.Interface()), 64)
+		if rules.Minimum != nil && n < *rules.Minimum {
+			errs.Addf(pointer, "minimum", "must be at least %v", *rules.Minimum)
+		}
+		if rules.Maximum != nil && n > *rules.Maximum {
+			errs.Addf(pointer, "maximum", "must be at most %v", *rules.Maximum)
+		}
+	}
+	if len(rules.Enum) > 0 && !slices.Contains(rules.Enum, fmt.Sprint(v.Interface())) {
+		errs.Addf(pointer, "enum", "must be one of %s", strings.Join(rules.Enum, ", "))
+	}
+}
@@ -0,0 +1,94 @@
+package resource_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type Address struct {
+	Street string `json:"street" validate:"required"`
+}
+
+type TaggedResource struct {
+	Name    string    `json:"name" validate:"required,max=8"`
+	Email   string    `json:"email,omitempty" validate:"email"`
+	Role    string    `json:"role,omitempty" validate:"enum=admin|member"`
+	Age     int       `json:"age" validate:"min=18"`
+	Tags    []string  `json:"tags,omitempty" validate:"maxItems=2"`
+	Code    string    `json:"code,omitempty" validate:"pattern=^[A-Z]{3}$"`
+	Address *Address  `json:"address"`
+	Others  []Address `json:"others"`
+}
+
+func (m TaggedResource) ValidateCreate(ctx resource.Context) error {
+	var v resource.ValidationError
+	if m.Name == "reserved" {
+		v.Add("/name", "reserved", "is reserved")
+	}
+	return v.Err()
+}
+
+func (m TaggedResource) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func TestValidateTags(t *testing.T) {
+	r := resource.New[TaggedResource]().
+		Name("tagged").
+		Plural("tagged").
+		Create(func(ctx resource.Context, r TaggedResource) (TaggedResource, error) { return r, nil })
+
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCodes  []string
+	}{
+		{"Valid", `{"name":"a","email":"a@example.com","role":"admin","age":20,"code":"ABC","address":{"street":"x"}}`, http.StatusCreated, nil},
+		{"Required", `{}`, http.StatusUnprocessableEntity, []string{"/name:required", "/age:minimum"}},
+		{"ZeroValue", `{"name":"a","age":0}`, http.StatusUnprocessableEntity, []string{"/age:minimum"}},
+		{"OmittedOptional", `{"name":"a","age":18}`, http.StatusCreated, nil},
+		{"Constraints", `{"name":"toolongname","email":"nope","role":"root","age":3,"tags":["a","b","c"],"code":"abc"}`, http.StatusUnprocessableEntity, []string{
+			"/name:maxLength", "/email:email", "/role:enum", "/age:minimum", "/tags:maxItems", "/code:pattern",
+		}},
+		{"Nested", `{"name":"a","age":18,"address":{},"others":[{"street":"x"},{}]}`, http.StatusUnprocessableEntity, []string{
+			"/address/street:required", "/others/1/street:required",
+		}},
+		{"ValidatorAfterTags", `{"name":"reserved","age":18}`, http.StatusUnprocessableEntity, []string{"/name:reserved"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/tagged", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					rr.Code, tc.expectedStatus)
+			}
+			if tc.expectedCodes == nil {
+				return
+			}
+			var errResp struct {
+				Error []resource.FieldError `json:"error"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+				t.Fatalf("Could not decode error response: %v", err)
+			}
+			var got []string
+			for _, f := range errResp.Error {
+				got = append(got, f.Pointer+":"+f.Code)
+			}
+			gotJSON, _ := json.Marshal(got)
+			expectedJSON, _ := json.Marshal(tc.expectedCodes)
+			if string(gotJSON) != string(expectedJSON) {
+				t.Errorf("Unexpected field errors: got %s want %s", gotJSON, expectedJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,543 @@
+// Package sqlstore implements the callbacks of a resource with database/sql.
+// Each exported field of T is a column, named by its db tag or else by its
+// json tag. The primary key is the column tagged with ",pk", or "id":
+//
+//	type User struct {
+//		ID    int64  `json:"id" db:"id,pk"`
+//		Name  string `json:"name" db:"name"`
+//		Email string `json:"email"`
+//	}
+//
+//	store, err := sqlstore.New[User](db, "users")
+//	store.Bind(users)
+//
+// Fields of kinds without a SQL counterpart, such as slices and structs
+// other than time.Time, are stored as JSON text.
+package sqlstore
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iwanhae/resource"
+)
+
+type column struct {
+	name  string // SQL name
+	field string // JSON name, used by list queries
+	index []int
+	json  bool // stored as JSON text
+}
+
+type Store[T resource.Validator] struct {
+	db      *sql.DB
+	table   string
+	columns []column
+	pk      column
+
+	placeholder     func(n int) string
+	uniqueViolation func(err error) bool
+	returning       bool
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// New maps T onto table. It fails when T has no primary key, or when a
+// table or column name is not a plain SQL identifier.
+func New[T resource.Validator](db *sql.DB, table string) (*Store[T], error) {
+	if !identifier.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+	s := &Store[T]{
+		db:              db,
+		table:           table,
+		placeholder:     func(n int) string { return "?" },
+		uniqueViolation: isUniqueViolation,
+	}
+
+	t := reflect.TypeFor[T]()
+	pk := -1
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		dbName, options, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if dbName == "-" {
+			continue
+		}
+		if dbName == "" {
+			dbName = jsonName
+		}
+		if !identifier.MatchString(dbName) {
+			return nil, fmt.Errorf("invalid column name %q of field %s", dbName, field.Name)
+		}
+
+		c := column{name: dbName, field: jsonName, index: field.Index, json: storedAsJSON(field.Type)}
+		s.columns = append(s.columns, c)
+		if options == "pk" || (pk < 0 && dbName == "id") {
+			pk = len(s.columns) - 1
+		}
+	}
+	if pk < 0 {
+		return nil, fmt.Errorf("%s has no primary key, tag a field with db:\"<name>,pk\"", t)
+	}
+	s.pk = s.columns[pk]
+	return s, nil
+}
+
+// Placeholder sets the bind parameter syntax, "?" by default. Use
+// func(n int) string { return "$" + strconv.Itoa(n) } for PostgreSQL,
+// along with Returning(true).
+func (s *Store[T]) Placeholder(placeholder func(n int) string) *Store[T] {
+	s.placeholder = placeholder
+	return s
+}
+
+// Returning reads generated primary keys with INSERT ... RETURNING instead
+// of LastInsertId, which drivers of PostgreSQL do not support. SQLite 3.35
+// and later support both.
+func (s *Store[T]) Returning(enabled bool) *Store[T] {
+	s.returning = enabled
+	return s
+}
+
+// UniqueViolation sets how errors of unique constraints, reported as
+// conflicts, are recognized. The default matches the messages of SQLite,
+// PostgreSQL and MySQL.
+func (s *Store[T]) UniqueViolation(uniqueViolation func(err error) bool) *Store[T] {
+	s.uniqueViolation = uniqueViolation
+	return s
+}
+
+// Bind sets every callback of r. The fields of list queries are the JSON
+// names of the columns.
+func (s *Store[T]) Bind(r *resource.Resource[T]) *resource.Resource[T] {
+	return r.
+		List(s.List).
+		Count(s.Count).
+		Create(s.Create).
+		Get(s.Get).
+		Update(s.Update).
+		Patch(s.Update).
+		Delete(s.Delete)
+}
+
+func (s *Store[T]) List(ctx resource.Context, query resource.ListQuery) ([]T, error) {
+	q := s.newQuery()
+	q.WriteString("SELECT " + s.columnList() + " FROM " + s.table)
+	if err := q.where(query.Filters); err != nil {
+		return nil, err
+	}
+	if len(query.Sort) > 0 {
+		keys := make([]string, 0, len(query.Sort))
+		for _, key := range query.Sort {
+			c, err := s.column(key.Field)
+			if err != nil {
+				return nil, err
+			}
+			if key.Desc {
+				keys = append(keys, c.name+" DESC")
+			} else {
+				keys = append(keys, c.name)
+			}
+		}
+		q.WriteString(" ORDER BY " + strings.Join(keys, ", "))
+	}
+	if query.Limit > 0 {
+		q.WriteString(" LIMIT " + q.arg(query.Limit) + " OFFSET " + q.arg(max(query.Offset, 0)))
+	}
+
+	rows, err := s.db.QueryContext(ctx, q.String(), q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []T{}
+	for rows.Next() {
+		item, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *Store[T]) Count(ctx resource.Context, query resource.ListQuery) (int, error) {
+	q := s.newQuery()
+	q.WriteString("SELECT COUNT(*) FROM " + s.table)
+	if err := q.where(query.Filters); err != nil {
+		return 0, err
+	}
+	var count int
+	err := s.db.QueryRowContext(ctx, q.String(), q.args...).Scan(&count)
+	return count, err
+}
+
+// Create inserts item. An empty primary key is generated: by the database
+// for integer keys, read with LastInsertId or else RETURNING, and as a
+// random hex string for string keys.
+func (s *Store[T]) Create(ctx resource.Context, item T) (T, error) {
+	v := reflect.ValueOf(&item).Elem()
+	pk := v.FieldByIndex(s.pk.index)
+	generated := pk.IsZero() && pk.Kind() != reflect.String
+	if pk.IsZero() && pk.Kind() == reflect.String {
+		pk.SetString(randomID())
+	}
+
+	q := s.newQuery()
+	var names, values []string
+	for _, c := range s.columns {
+		if generated && c.name == s.pk.name {
+			continue
+		}
+		value, err := c.value(v)
+		if err != nil {
+			return item, err
+		}
+		names = append(names, c.name)
+		values = append(values, q.arg(value))
+	}
+	q.WriteString("INSERT INTO " + s.table + " (" + strings.Join(names, ", ") + ") VALUES (" + strings.Join(values, ", ") + ")")
+	if generated && s.returning {
+		q.WriteString(" RETURNING " + s.pk.name)
+		var n int64
+		if err := s.db.QueryRowContext(ctx, q.String(), q.args...).Scan(&n); err != nil {
+			return item, s.execError(err)
+		}
+		return s.Get(ctx, strconv.FormatInt(n, 10))
+	}
+	result, err := s.db.ExecContext(ctx, q.String(), q.args...)
+	if err != nil {
+		return item, s.execError(err)
+	}
+
+	id := fmt.Sprint(pk.Interface())
+	if generated {
+		n, err := result.LastInsertId()
+		if err != nil {
+			return item, err
+		}
+		id = strconv.FormatInt(n, 10)
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *Store[T]) Get(ctx resource.Context, id string) (T, error) {
+	return s.get(ctx, s.db, id)
+}
+
+func (s *Store[T]) get(ctx resource.Context, db querier, id string) (T, error) {
+	var item T
+	key, err := s.key(id)
+	if err != nil {
+		return item, err
+	}
+	q := s.newQuery()
+	q.WriteString("SELECT " + s.columnList() + " FROM " + s.table + " WHERE " + s.pk.name + " = " + q.arg(key))
+	item, err = s.scan(db.QueryRowContext(ctx, q.String(), q.args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return item, notFound(id)
+	}
+	return item, err
+}
+
+// Update replaces every column of the row with the given id. Update and
+// Delete check the If-Match header of the request against the row in the
+// same serializable transaction as the write, see resource.Versioner.
+func (s *Store[T]) Update(ctx resource.Context, id string, item T) (T, error) {
+	key, err := s.key(id)
+	if err != nil {
+		return item, err
+	}
+	v := reflect.ValueOf(&item).Elem()
+	q := s.newQuery()
+	var sets []string
+	for _, c := range s.columns {
+		if c.name == s.pk.name {
+			continue
+		}
+		value, err := c.value(v)
+		if err != nil {
+			return item, err
+		}
+		sets = append(sets, c.name+" = "+q.arg(value))
+	}
+	q.WriteString("UPDATE " + s.table + " SET " + strings.Join(sets, ", ") + " WHERE " + s.pk.name + " = " + q.arg(key))
+	err = s.conditionally(ctx, id, func(db querier) error {
+		if _, err := db.ExecContext(ctx, q.String(), q.args...); err != nil {
+			return s.execError(err)
+		}
+		// rows affected is not reliable as some drivers do not count
+		// unchanged rows, so a missing row is reported by get
+		item, err = s.get(ctx, db, id)
+		return err
+	})
+	return item, err
+}
+
+func (s *Store[T]) Delete(ctx resource.Context, id string) error {
+	key, err := s.key(id)
+	if err != nil {
+		return err
+	}
+	q := s.newQuery()
+	q.WriteString("DELETE FROM " + s.table + " WHERE " + s.pk.name + " = " + q.arg(key))
+	return s.conditionally(ctx, id, func(db querier) error {
+		result, err := db.ExecContext(ctx, q.String(), q.args...)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return notFound(id)
+		}
+		return nil
+	})
+}
+
+func (s *Store[T]) columnList() string {
+	names := make([]string, len(s.columns))
+	for i, c := range s.columns {
+		names[i] = c.name
+	}
+	return strings.Join(names, ", ")
+}
+
+// column returns the column of a field of a list query.
+func (s *Store[T]) column(field string) (column, error) {
+	for _, c := range s.columns {
+		if c.field == field {
+			return c, nil
+		}
+	}
+	return column{}, resource.ErrBadRequest.WithMessage(fmt.Sprintf("unknown field %q", field))
+}
+
+// key converts an id from a path to the type of the primary key.
+func (s *Store[T]) key(id string) (any, error) {
+	var item T
+	value, err := convert(reflect.ValueOf(&item).Elem().FieldByIndex(s.pk.index).Type(), id)
+	if err != nil {
+		return nil, notFound(id)
+	}
+	return value, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func (s *Store[T]) scan(row scanner) (T, error) {
+	var item T
+	v := reflect.ValueOf(&item).Elem()
+	dest := make([]any, len(s.columns))
+	raw := make([]sql.NullString, len(s.columns)) // JSON text of the JSON columns
+	for i, c := range s.columns {
+		if c.json {
+			dest[i] = &raw[i]
+		} else {
+			dest[i] = v.FieldByIndex(c.index).Addr().Interface()
+		}
+	}
+	if err := row.Scan(dest...); err != nil {
+		return item, err
+	}
+	for i, c := range s.columns {
+		if !c.json || !raw[i].Valid || raw[i].String == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(raw[i].String), v.FieldByIndex(c.index).Addr().Interface()); err != nil {
+			return item, fmt.Errorf("failed to decode column %s: %w", c.name, err)
+		}
+	}
+	return item, nil
+}
+
+// querier is implemented by *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conditionally runs write on the database, or for requests with an If-Match
+// header, in a serializable transaction which first checks the header
+// against the row with the given id.
+func (s *Store[T]) conditionally(ctx resource.Context, id string, write func(db querier) error) error {
+	if ctx.IfMatch() == "" {
+		return write(s.db)
+	}
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	current, err := s.get(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := ctx.CheckIfMatch(current); err != nil {
+		return err
+	}
+	if err := write(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store[T]) execError(err error) error {
+	if s.uniqueViolation(err) {
+		return resource.ErrConflict.Wrap(err)
+	}
+	return err
+}
+
+// value returns the column value of the item v.
+func (c column) value(v reflect.Value) (any, error) {
+	field := v.FieldByIndex(c.index)
+	if !c.json {
+		return field.Interface(), nil
+	}
+	raw, err := json.Marshal(field.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode column %s: %w", c.name, err)
+	}
+	return string(raw), nil
+}
+
+// query builds a statement and its arguments.
+type query struct {
+	strings.Builder
+	args        []any
+	placeholder func(n int) string
+	columns     func(field string) (column, error)
+	fieldType   func(c column) reflect.Type
+}
+
+func (s *Store[T]) newQuery() *query {
+	return &query{
+		placeholder: s.placeholder,
+		columns:     s.column,
+		fieldType: func(c column) reflect.Type {
+			return reflect.TypeFor[T]().FieldByIndex(c.index).Type
+		},
+	}
+}
+
+func (q *query) arg(value any) string {
+	q.args = append(q.args, value)
+	return q.placeholder(len(q.args))
+}
+
+var operators = map[resource.FilterOperator]string{
+	resource.FilterEqual:          "=",
+	resource.FilterNotEqual:       "<>",
+	resource.FilterGreater:        ">",
+	resource.FilterGreaterOrEqual: ">=",
+	resource.FilterLess:           "<",
+	resource.FilterLessOrEqual:    "<=",
+}
+
+func (q *query) where(filters []resource.Filter) error {
+	for i, f := range filters {
+		c, err := q.columns(f.Field)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			q.WriteString(" WHERE ")
+		} else {
+			q.WriteString(" AND ")
+		}
+		if f.Operator == resource.FilterContains {
+			escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(f.Value)
+			q.WriteString(c.name + " LIKE " + q.arg("%"+escaped+"%") + ` ESCAPE '\'`)
+			continue
+		}
+		op, ok := operators[f.Operator]
+		if !ok {
+			return resource.ErrBadRequest.WithMessage(fmt.Sprintf("unsupported operator %q", f.Operator))
+		}
+		var value any = f.Value
+		if !c.json {
+			if value, err = convert(q.fieldType(c), f.Value); err != nil {
+				return resource.ErrBadRequest.WithMessage(fmt.Sprintf("invalid value %q for field %q", f.Value, f.Field))
+			}
+		}
+		q.WriteString(c.name + " " + op + " " + q.arg(value))
+	}
+	return nil
+}
+
+// convert parses s as a value of type t, for use as a query argument.
+func convert(t reflect.Type, s string) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	}
+	if t == timeType {
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	return s, nil
+}
+
+func storedAsJSON(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Map, reflect.Array, reflect.Interface:
+		return true
+	case reflect.Struct:
+		return t != timeType && !reflect.PointerTo(t).Implements(reflect.TypeFor[sql.Scanner]())
+	}
+	return false
+}
+
+func isUniqueViolation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") || // SQLite
+		strings.Contains(msg, "duplicate key value") || // PostgreSQL
+		strings.Contains(msg, "Duplicate entry") // MySQL
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("failed to generate id: %w", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+func notFound(id string) error {
+	return resource.ErrNotFound.WithMessage(fmt.Sprintf("%q not found", id))
+}
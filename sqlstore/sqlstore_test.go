@@ -0,0 +1,200 @@
+package sqlstore_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iwanhae/resource"
+	"github.com/iwanhae/resource/sqlstore"
+	_ "modernc.org/sqlite"
+)
+
+type User struct {
+	ID    int64    `json:"id"`
+	Name  string   `json:"name" db:"full_name"`
+	Email string   `json:"email"`
+	Age   int      `json:"age"`
+	Tags  []string `json:"tags"`
+	Token string   `json:"-"`
+}
+
+func (u User) ValidateCreate(ctx resource.Context) error            { return nil }
+func (u User) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+func newUsers(t *testing.T, returning bool) http.Handler {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(`CREATE TABLE users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		full_name TEXT NOT NULL,
+		email TEXT NOT NULL UNIQUE,
+		age INTEGER NOT NULL,
+		tags TEXT
+	)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := sqlstore.New[User](db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Returning(returning)
+	users := resource.New[User]().
+		Name("user").
+		Plural("users").
+		Filterable("name", "age", "email").
+		Sortable("age", "name")
+	return store.Bind(users).Handler()
+}
+
+func TestStore(t *testing.T) {
+	t.Run("LastInsertId", func(t *testing.T) { testStore(t, newUsers(t, false)) })
+	t.Run("Returning", func(t *testing.T) { testStore(t, newUsers(t, true)) })
+}
+
+func testStore(t *testing.T, handler http.Handler) {
+
+	testCases := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"CreateAlice", "POST", "/users", `{"name":"alice","email":"alice@example.com","age":30,"tags":["admin"]}`, http.StatusCreated,
+			`{"id":1,"name":"alice","email":"alice@example.com","age":30,"tags":["admin"]}`},
+		{"CreateBob", "POST", "/users", `{"name":"bob","email":"bob@example.com","age":20}`, http.StatusCreated,
+			`{"id":2,"name":"bob","email":"bob@example.com","age":20,"tags":null}`},
+		{"CreateConflict", "POST", "/users", `{"name":"eve","email":"bob@example.com","age":20}`, http.StatusConflict, ""},
+		{"Get", "GET", "/users/1", "", http.StatusOK, `{"id":1,"name":"alice","email":"alice@example.com","age":30,"tags":["admin"]}`},
+		{"GetNotFound", "GET", "/users/9", "", http.StatusNotFound, ""},
+		{"GetInvalidID", "GET", "/users/abc", "", http.StatusNotFound, ""},
+		{"Update", "PUT", "/users/2", `{"name":"bobby","email":"bob@example.com","age":21}`, http.StatusOK,
+			`{"id":2,"name":"bobby","email":"bob@example.com","age":21,"tags":null}`},
+		{"UpdateNotFound", "PUT", "/users/9", `{"name":"x","email":"x@example.com"}`, http.StatusNotFound, ""},
+		{"ListFiltered", "GET", `/users?filter=age+gt+25`, "", http.StatusOK,
+			`{"items":[{"id":1,"name":"alice","email":"alice@example.com","age":30,"tags":["admin"]}],"metadata":{"offset":0,"limit":10,"total":1,"hasMore":false}}`},
+		{"ListContains", "GET", `/users?filter=name+contains+"ob"&sort=-age`, "", http.StatusOK,
+			`{"items":[{"id":2,"name":"bobby","email":"bob@example.com","age":21,"tags":null}],"metadata":{"offset":0,"limit":10,"total":1,"hasMore":false}}`},
+		{"ListSortedPage", "GET", `/users?sort=name&limit=1&offset=1`, "", http.StatusOK,
+			`{"items":[{"id":2,"name":"bobby","email":"bob@example.com","age":21,"tags":null}],"metadata":{"offset":1,"limit":1,"total":2,"hasMore":false}}`},
+		{"ListInvalidNumber", "GET", `/users?age=old`, "", http.StatusBadRequest, ""},
+		{"Delete", "DELETE", "/users/1", "", http.StatusNoContent, ""},
+		{"DeleteNotFound", "DELETE", "/users/1", "", http.StatusNotFound, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+			if tc.expectedBody == "" {
+				return
+			}
+			var got, expected any
+			json.Unmarshal(rr.Body.Bytes(), &got)
+			json.Unmarshal([]byte(tc.expectedBody), &expected)
+			gotJSON, _ := json.Marshal(got)
+			expectedJSON, _ := json.Marshal(expected)
+			if string(gotJSON) != string(expectedJSON) {
+				t.Errorf("handler returned unexpected body: got %s want %s", gotJSON, expectedJSON)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := sqlstore.New[User](nil, "users; DROP TABLE users"); err == nil {
+		t.Errorf("expected an error for an invalid table name")
+	}
+	if _, err := sqlstore.New[noKey](nil, "things"); err == nil {
+		t.Errorf("expected an error for a type without primary key")
+	}
+}
+
+type noKey struct {
+	Name string `json:"name"`
+}
+
+func (noKey) ValidateCreate(ctx resource.Context) error            { return nil }
+func (noKey) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+
+type Document struct {
+	ID       int64  `json:"id"`
+	Revision string `json:"revision"`
+}
+
+func (d Document) ValidateCreate(ctx resource.Context) error            { return nil }
+func (d Document) ValidateUpdate(ctx resource.Context, id string) error { return nil }
+func (d Document) Version() string                                      { return d.Revision }
+
+func TestIfMatch(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`CREATE TABLE docs (id INTEGER PRIMARY KEY, revision TEXT NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO docs (id, revision) VALUES (1, '2')`); err != nil {
+		t.Fatal(err)
+	}
+	store, err := sqlstore.New[Document](db, "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a stale Get passes the check of the handler, as a concurrent write
+	// would, so that the precondition is left to the store
+	handler := store.Bind(resource.New[Document]().Name("doc").Plural("docs")).
+		Get(func(ctx resource.Context, id string) (Document, error) {
+			return Document{ID: 1, Revision: "1"}, nil
+		}).
+		Handler()
+
+	testCases := []struct {
+		name           string
+		method         string
+		ifMatch        string
+		expectedStatus int
+	}{
+		{"UpdateStale", "PUT", `"1"`, http.StatusPreconditionFailed},
+		{"DeleteStale", "DELETE", `"1"`, http.StatusPreconditionFailed},
+		{"UpdateAny", "PUT", `*`, http.StatusOK},
+		{"DeleteAny", "DELETE", `*`, http.StatusNoContent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/docs/1", strings.NewReader(`{"revision":"3"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", tc.ifMatch)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s",
+					rr.Code, tc.expectedStatus, rr.Body.String())
+			}
+		})
+	}
+}
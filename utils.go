@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 func JSON(w http.ResponseWriter, code int, body interface{}) {
@@ -14,16 +15,27 @@ func JSON(w http.ResponseWriter, code int, body interface{}) {
 	json.NewEncoder(w).Encode(body)
 }
 
+// JSONError writes err as an ErrorResponse. code is used unless err carries
+// its own status code (see Error and StatusCoder).
 func JSONError(w http.ResponseWriter, code int, err error) {
+	code, res := newErrorResponse(code, err)
+	JSON(w, code, res)
+}
+
+func newErrorResponse(code int, err error) (int, ErrorResponse) {
+	code = statusFromError(err, code)
 	res := ErrorResponse{
 		Code:    code,
+		Reason:  reasonFromError(err),
 		Message: err.Error(),
 	}
-	unwrapped := errors.Unwrap(err)
-	if unwrapped != nil {
-		res.Error = unwrapped
+	var fe FieldErrorer
+	if errors.As(err, &fe) {
+		res.Error = fe.FieldErrors()
+	} else {
+		res.Error = errorDetail(errors.Unwrap(err))
 	}
-	JSON(w, code, res)
+	return code, res
 }
 
 func parseParamsInt(r *http.Request, key string, defaultValue int) (int, error) {
@@ -43,3 +55,26 @@ func newContext(r *http.Request) Context {
 		Context: r.Context(),
 	}
 }
+
+// cleanBase normalizes a path prefix to have a leading and no trailing slash.
+// The root prefix is represented as an empty string.
+func cleanBase(base string) string {
+	base = strings.Trim(base, "/")
+	if base == "" {
+		return ""
+	}
+	return "/" + base
+}
+
+// errorDetail is the ErrorResponse.Error of a wrapped error. Errors are
+// mostly structs without exported fields, which would be encoded as {},
+// so they are reported by their message unless they marshal themselves.
+func errorDetail(err error) interface{} {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case json.Marshaler:
+		return e
+	}
+	return err.Error()
+}
@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes why a single field of a request is invalid.
+// Pointer is the RFC 6901 JSON pointer of a body field, e.g. /items/0/name,
+// and Parameter the name of a path, query or header parameter.
+type FieldError struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message"`
+}
+
+// FieldErrorer is implemented by errors which can be reported per field.
+type FieldErrorer interface {
+	FieldErrors() []FieldError
+}
+
+// ValidationError collects the field errors of a request body, to be
+// reported all at once with 422 Unprocessable Entity:
+//
+//	var v resource.ValidationError
+//	if m.Name == "" {
+//		v.Add("/name", "required", "must not be empty")
+//	}
+//	return v.Err()
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Add records an error of the field at pointer.
+func (e *ValidationError) Add(pointer string, code string, message string) {
+	e.Errors = append(e.Errors, FieldError{Pointer: pointer, Code: code, Message: message})
+}
+
+// Addf is Add with a formatted message.
+func (e *ValidationError) Addf(pointer string, code string, format string, args ...any) {
+	e.Add(pointer, code, fmt.Sprintf(format, args...))
+}
+
+// Merge records the field errors of err below prefix, e.g. those returned
+// by the validation of a nested struct. Other errors are recorded at prefix.
+func (e *ValidationError) Merge(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	fe, ok := err.(FieldErrorer)
+	if !ok {
+		e.Add(prefix, "", err.Error())
+		return
+	}
+	for _, f := range fe.FieldErrors() {
+		f.Pointer = prefix + f.Pointer
+		e.Errors = append(e.Errors, f)
+	}
+}
+
+// Err returns e, or nil when no errors were recorded.
+func (e *ValidationError) Err() error {
+	if e == nil || len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, f := range e.Errors {
+		msgs[i] = f.Message
+		if f.Pointer != "" {
+			msgs[i] = f.Pointer + ": " + f.Message
+		} else if f.Parameter != "" {
+			msgs[i] = f.Parameter + ": " + f.Message
+		}
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) FieldErrors() []FieldError {
+	return e.Errors
+}
+
+func (e *ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Unwrap makes errors.Is(err, ErrUnprocessable) hold.
+func (e *ValidationError) Unwrap() error {
+	return ErrUnprocessable
+}
+
+// Pointer builds a JSON pointer from reference tokens, e.g. Pointer("items", 0, "name")
+// returns /items/0/name.
+func Pointer(tokens ...any) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		switch t := token.(type) {
+		case int:
+			sb.WriteString(strconv.Itoa(t))
+		default:
+			s := fmt.Sprint(t)
+			sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1"))
+		}
+	}
+	return sb.String()
+}
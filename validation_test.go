@@ -0,0 +1,118 @@
+package resource_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/iwanhae/resource"
+)
+
+type ValidatedResource struct {
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+	Tags  []string `json:"tags"`
+}
+
+func (m ValidatedResource) ValidateCreate(ctx resource.Context) error {
+	var v resource.ValidationError
+	if m.Name == "" {
+		v.Add("/name", "required", "must not be empty")
+	}
+	if m.Email == "" {
+		v.Add("/email", "required", "must not be empty")
+	}
+	for i, tag := range m.Tags {
+		if len(tag) > 3 {
+			v.Addf(resource.Pointer("tags", i), "maxLength", "must be at most %d characters", 3)
+		}
+	}
+	return v.Err()
+}
+
+func (m ValidatedResource) ValidateUpdate(ctx resource.Context, id string) error {
+	return m.ValidateCreate(ctx)
+}
+
+func TestValidationError(t *testing.T) {
+	r := resource.New[ValidatedResource]().
+		Name("user").
+		Plural("users").
+		Create(func(ctx resource.Context, r ValidatedResource) (ValidatedResource, error) { return r, nil })
+
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedErrors []resource.FieldError
+	}{
+		{"Valid", `{"name":"a","email":"a@example.com"}`, http.StatusCreated, nil},
+		{"Invalid", `{"tags":["ok","long"]}`, http.StatusUnprocessableEntity, []resource.FieldError{
+			{Pointer: "/name", Code: "required", Message: "must not be empty"},
+			{Pointer: "/email", Code: "required", Message: "must not be empty"},
+			{Pointer: "/tags/1", Code: "maxLength", Message: "must be at most 3 characters"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			r.Handler().ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					rr.Code, tc.expectedStatus)
+			}
+			if tc.expectedErrors == nil {
+				return
+			}
+
+			var errResp struct {
+				Reason string                `json:"reason"`
+				Error  []resource.FieldError `json:"error"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
+				t.Fatalf("Could not decode error response: %v", err)
+			}
+			if errResp.Reason != "Unprocessable" {
+				t.Errorf("Unexpected reason: got %v", errResp.Reason)
+			}
+			got, _ := json.Marshal(errResp.Error)
+			expected, _ := json.Marshal(tc.expectedErrors)
+			if string(got) != string(expected) {
+				t.Errorf("Unexpected field errors: got %s want %s", got, expected)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMerge(t *testing.T) {
+	var inner resource.ValidationError
+	inner.Add("/street", "required", "must not be empty")
+
+	var v resource.ValidationError
+	v.Merge("/address", inner.Err())
+	v.Merge("/phone", errors.New("invalid phone number"))
+	v.Merge("/email", nil)
+
+	expected := []resource.FieldError{
+		{Pointer: "/address/street", Code: "required", Message: "must not be empty"},
+		{Pointer: "/phone", Message: "invalid phone number"},
+	}
+	got, _ := json.Marshal(v.FieldErrors())
+	want, _ := json.Marshal(expected)
+	if string(got) != string(want) {
+		t.Errorf("Unexpected field errors: got %s want %s", got, want)
+	}
+	if !errors.Is(v.Err(), resource.ErrUnprocessable) {
+		t.Errorf("expected %v to match ErrUnprocessable", v.Err())
+	}
+	if got := resource.Pointer("a/b", "c~d", 2); got != "/a~1b/c~0d/2" {
+		t.Errorf("Unexpected pointer: got %v", got)
+	}
+}